@@ -0,0 +1,141 @@
+package gctuner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// accountant is a named source of memory used outside of the Go heap.
+type accountant struct {
+	name string
+	fn   func() uint64
+}
+
+// accountants is an immutable list of accountants sorted by name.
+type accountants []accountant
+
+// RegisterAccountant registers fn under name with the default tuner. See
+// [Tuner.RegisterAccountant].
+func RegisterAccountant(name string, fn func() uint64) error {
+	return std.RegisterAccountant(name, fn)
+}
+
+// UnregisterAccountant removes the accountant registered under name with the
+// default tuner. See [Tuner.UnregisterAccountant].
+func UnregisterAccountant(name string) {
+	std.UnregisterAccountant(name)
+}
+
+// RegisterAccountant registers fn as the accountant for name.
+//
+// An accountant reports memory held outside of the Go heap, in bytes, such
+// as mmap'd files, cgo allocations, or off-heap caches. On every tuning step,
+// the sum of all accountants is subtracted from the threshold before the GC
+// percent is computed, and from the Go memory limit before it is applied.
+//
+// Accountants are called on the runtime finalizer goroutine after each GC
+// cycle, so they must be fast, must not block, and must be safe for
+// concurrent use. RegisterAccountant returns an error if name is empty or
+// already registered, or if fn is nil.
+func (t *Tuner) RegisterAccountant(name string, fn func() uint64) error {
+	if name == "" {
+		return fmt.Errorf("invalid accountant name: empty")
+	}
+
+	if fn == nil {
+		return fmt.Errorf("invalid accountant %q: nil", name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.getAccountants()
+	for _, a := range prev {
+		if a.name == name {
+			return fmt.Errorf("accountant %q already registered", name)
+		}
+	}
+
+	next := make(accountants, 0, len(prev)+1)
+	next = append(next, prev...)
+	next = append(next, accountant{name: name, fn: fn})
+	sort.Slice(next, func(i, j int) bool { return next[i].name < next[j].name })
+	t.accountants.Store(next)
+
+	return nil
+}
+
+// UnregisterAccountant removes the accountant registered under name, if any.
+func (t *Tuner) UnregisterAccountant(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.getAccountants()
+	next := make(accountants, 0, len(prev))
+	for _, a := range prev {
+		if a.name != name {
+			next = append(next, a)
+		}
+	}
+
+	if len(next) != len(prev) {
+		t.accountants.Store(next)
+	}
+}
+
+// Accountants returns the names of the registered accountants, sorted.
+func (t *Tuner) Accountants() []string {
+	list := t.getAccountants()
+	names := make([]string, len(list))
+	for i, a := range list {
+		names[i] = a.name
+	}
+
+	return names
+}
+
+func (t *Tuner) getAccountants() accountants {
+	list, _ := t.accountants.Load().(accountants)
+
+	return list
+}
+
+// nonHeap returns the memory used outside of the Go heap, in bytes: the sum
+// of the registered accountants, and of the runtime non-heap memory classes
+// when [WithRuntimeAccountant] is set. external is the part that the Go
+// memory limit does not already cover, i.e. without the runtime classes.
+func (t *Tuner) nonHeap() (total, external uint64) {
+	for _, a := range t.getAccountants() {
+		external = addSaturating(external, a.fn())
+	}
+
+	total = external
+	if atomic.LoadUint32(&t.runtimeAccountant) != 0 {
+		total = addSaturating(total, readNonHeap())
+	}
+
+	return total, external
+}
+
+// subtractFloor returns n - sub, or 1 if sub consumes n entirely. A zero n is
+// returned as is, since it means unset.
+func subtractFloor(n, sub uint64) uint64 {
+	switch {
+	case n == 0:
+		return 0
+	case sub < n:
+		return n - sub
+	default:
+		return 1
+	}
+}
+
+func addSaturating(a, b uint64) uint64 {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64
+	}
+
+	return a + b
+}
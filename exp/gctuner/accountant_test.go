@@ -0,0 +1,143 @@
+package gctuner
+
+import (
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRegisterAccountant(t *testing.T) {
+	tn := newTunerInstance()
+
+	if err := tn.RegisterAccountant("", func() uint64 { return 0 }); err == nil {
+		t.Fatal("expected error for empty name")
+	}
+
+	if err := tn.RegisterAccountant("mmap", nil); err == nil {
+		t.Fatal("expected error for nil accountant")
+	}
+
+	if err := tn.RegisterAccountant("mmap", func() uint64 { return 1 }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tn.RegisterAccountant("cgo", func() uint64 { return 2 }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tn.RegisterAccountant("mmap", func() uint64 { return 3 }); err == nil {
+		t.Fatal("expected error for duplicate name")
+	}
+
+	if got, want := tn.Accountants(), []string{"cgo", "mmap"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected accountants %v, got %v", want, got)
+	}
+
+	if total, external := tn.nonHeap(); total != 3 || external != 3 {
+		t.Fatalf("expected non-heap 3/3, got %d/%d", total, external)
+	}
+
+	tn.UnregisterAccountant("cgo")
+	tn.UnregisterAccountant("missing")
+
+	if got, want := tn.Accountants(), []string{"mmap"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected accountants %v, got %v", want, got)
+	}
+}
+
+func TestTunerTuneAccountants(t *testing.T) {
+	mem := &fakeMemory{inuse: 1 << 30, limit: 8 << 30}
+	rt := &fakeRuntime{gcPercent: 100}
+
+	tn, err := New(
+		WithMemorySource(mem),
+		WithRuntime(rt),
+		WithThreshold(5<<30),
+		WithMinGCPercent(20),
+		WithMaxGCPercent(1000),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	var mapped uint64 = 1 << 30
+	if err := tn.RegisterAccountant("mmap", func() uint64 { return atomic.LoadUint64(&mapped) }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 5 GiB - 1 GiB leaves a 4 GiB heap threshold.
+	d := tn.Tune()
+	if d.GCPercent != 300 || d.Threshold != 4<<30 || d.NonHeap != 1<<30 {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+
+	if s := tn.Stats(); s.NonHeap != 1<<30 || s.Threshold != 5<<30 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	// Non-heap memory beyond the threshold clamps to the minimum.
+	atomic.StoreUint64(&mapped, 6<<30)
+	if d := tn.Tune(); d.GCPercent != 20 || d.Threshold != 1 {
+		t.Fatalf("expected decision clamped to min 20, got %+v", d)
+	}
+}
+
+func TestTunerRuntimeAccountant(t *testing.T) {
+	tn, err := New(
+		WithMemorySource(&fakeMemory{inuse: 1 << 30}),
+		WithRuntime(&fakeRuntime{}),
+		WithThreshold(4<<30),
+		WithRuntimeAccountant(true),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	total, external := tn.nonHeap()
+	if total == 0 || external != 0 {
+		t.Fatalf("expected only runtime non-heap memory, got %d/%d", total, external)
+	}
+
+	if d := tn.Tune(); d.NonHeap == 0 || d.Threshold >= 4<<30 {
+		t.Fatalf("expected runtime non-heap memory to lower the threshold, got %+v", d)
+	}
+
+	if err := tn.Update(WithRuntimeAccountant(false)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if total, _ := tn.nonHeap(); total != 0 {
+		t.Fatalf("expected no non-heap memory, got %d", total)
+	}
+}
+
+func TestReadNonHeap(t *testing.T) {
+	done := make(chan struct{})
+	for i := 0; i < 100; i++ {
+		go func() { <-done }()
+	}
+	defer close(done)
+
+	runtime.Gosched()
+
+	if got := readNonHeap(); got == 0 {
+		t.Fatal("expected non-heap runtime memory")
+	}
+}
+
+func TestSubtractFloor(t *testing.T) {
+	for _, tt := range []struct{ n, sub, want uint64 }{
+		{0, 10, 0},
+		{100, 0, 100},
+		{100, 40, 60},
+		{100, 100, 1},
+		{100, 200, 1},
+	} {
+		if got := subtractFloor(tt.n, tt.sub); got != tt.want {
+			t.Fatalf("subtractFloor(%d, %d) = %d, want %d", tt.n, tt.sub, got, tt.want)
+		}
+	}
+}
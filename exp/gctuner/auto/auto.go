@@ -1,24 +1,57 @@
 package auto
 
-import "go.dw1.io/x/exp/gctuner"
+import (
+	"log"
+	"os"
+
+	"go.dw1.io/x/exp/gctuner"
+)
 
 var (
-	// MemLimitPercent is the default memory limit (bytes) used by auto.
-	// It is derived from 70% of the effective memory limit at init time.
-	MemLimitPercent = gctuner.GetMemLimitPercent(70)
+	// MemLimitPercent is the percent of the effective memory limit used as
+	// the Go memory limit and, unless GCTUNER_THRESHOLD is set, as the
+	// threshold. It holds the resolved value after init.
+	MemLimitPercent float64 = defaultMemLimitPercent
 
-	// MinGCPercent is the default minimum GOGC bound used by auto.
+	// MinGCPercent is the minimum GOGC bound used by auto. It holds the
+	// resolved value after init.
 	MinGCPercent = gctuner.GetMinGCPercent()
 
-	// MaxGCPercent is the default maximum GOGC bound used by auto.
+	// MaxGCPercent is the maximum GOGC bound used by auto. It holds the
+	// resolved value after init.
 	MaxGCPercent = gctuner.GetMaxGCPercent()
 )
 
 func init() {
-	gctuner.SetMemLimitPercent(float64(MemLimitPercent))
-	gctuner.MustEnable(
-		-1,
-		gctuner.WithMinGCPercent(MinGCPercent),
-		gctuner.WithMaxGCPercent(MaxGCPercent),
+	cfg, err := loadConfig(os.Getenv, MinGCPercent, MaxGCPercent)
+	if err != nil {
+		log.Printf("gctuner/auto: not enabled: %v", err)
+
+		return
+	}
+
+	if cfg.disabled {
+		log.Printf("gctuner/auto: disabled by %s", EnvDisable)
+
+		return
+	}
+
+	MemLimitPercent = cfg.memLimitPercent
+	MinGCPercent = cfg.minGCPercent
+	MaxGCPercent = cfg.maxGCPercent
+
+	gctuner.SetMemLimitPercent(cfg.memLimitPercent)
+
+	err = gctuner.Enable(
+		cfg.threshold,
+		gctuner.WithMinGCPercent(cfg.minGCPercent),
+		gctuner.WithMaxGCPercent(cfg.maxGCPercent),
 	)
+	if err != nil {
+		log.Printf("gctuner/auto: not enabled: %v", err)
+
+		return
+	}
+
+	log.Printf("gctuner/auto: enabled: %s (threshold %d bytes)", cfg, gctuner.Stats().Threshold)
 }
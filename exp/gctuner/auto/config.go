@@ -0,0 +1,122 @@
+package auto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.dw1.io/x/exp/gctuner/internal/bytesize"
+)
+
+// Environment variables read by auto during init.
+const (
+	// EnvDisable disables auto when set to a true value ("1", "true", ...).
+	EnvDisable = "GCTUNER_DISABLE"
+	// EnvMemLimitPercent is the percent of the effective memory limit used
+	// as the Go memory limit and threshold, in (0, 100]. Defaults to 70.
+	EnvMemLimitPercent = "GCTUNER_MEMLIMIT_PERCENT"
+	// EnvThreshold is an explicit heap threshold, as a byte count such as
+	// "2GiB" (the GOMEMLIMIT format). It overrides the threshold derived from
+	// EnvMemLimitPercent.
+	EnvThreshold = "GCTUNER_THRESHOLD"
+	// EnvMinGCPercent is the minimum GC percent.
+	EnvMinGCPercent = "GCTUNER_MIN_GC_PERCENT"
+	// EnvMaxGCPercent is the maximum GC percent.
+	EnvMaxGCPercent = "GCTUNER_MAX_GC_PERCENT"
+)
+
+// defaultMemLimitPercent is the recommended share of the memory limit.
+const defaultMemLimitPercent = 70
+
+type config struct {
+	disabled        bool
+	memLimitPercent float64
+	threshold       int64 // -1 derives it from memLimitPercent
+	minGCPercent    uint32
+	maxGCPercent    uint32
+}
+
+func (c config) String() string {
+	if c.disabled {
+		return "disabled"
+	}
+
+	threshold := "derived"
+	if c.threshold > 0 {
+		threshold = strconv.FormatInt(c.threshold, 10)
+	}
+
+	return fmt.Sprintf("memlimit_percent=%g threshold=%s min_gc_percent=%d max_gc_percent=%d",
+		c.memLimitPercent, threshold, c.minGCPercent, c.maxGCPercent)
+}
+
+// loadConfig reads the configuration from getenv, starting from the given
+// GC percent bounds.
+func loadConfig(getenv func(string) string, minGCPercent, maxGCPercent uint32) (config, error) {
+	cfg := config{
+		memLimitPercent: defaultMemLimitPercent,
+		threshold:       -1,
+		minGCPercent:    minGCPercent,
+		maxGCPercent:    maxGCPercent,
+	}
+
+	if v := strings.TrimSpace(getenv(EnvDisable)); v != "" {
+		disabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %v", EnvDisable, v, err)
+		}
+
+		if disabled {
+			cfg.disabled = true
+
+			return cfg, nil
+		}
+	}
+
+	if v := strings.TrimSpace(getenv(EnvMemLimitPercent)); v != "" {
+		percent, err := strconv.ParseFloat(v, 64)
+		if err != nil || !(percent > 0 && percent <= 100) {
+			return cfg, fmt.Errorf("invalid %s %q: must be a number in (0, 100]", EnvMemLimitPercent, v)
+		}
+
+		cfg.memLimitPercent = percent
+	}
+
+	if v := strings.TrimSpace(getenv(EnvThreshold)); v != "" {
+		n, ok := bytesize.Parse(v)
+		if !ok || n == 0 {
+			return cfg, fmt.Errorf("invalid %s %q: must be a positive byte count such as 2GiB", EnvThreshold, v)
+		}
+
+		cfg.threshold = n
+	}
+
+	var err error
+	if cfg.minGCPercent, err = parsePercent(getenv, EnvMinGCPercent, cfg.minGCPercent); err != nil {
+		return cfg, err
+	}
+
+	if cfg.maxGCPercent, err = parsePercent(getenv, EnvMaxGCPercent, cfg.maxGCPercent); err != nil {
+		return cfg, err
+	}
+
+	if cfg.minGCPercent > cfg.maxGCPercent {
+		return cfg, fmt.Errorf("%s %d is greater than %s %d", EnvMinGCPercent, cfg.minGCPercent, EnvMaxGCPercent, cfg.maxGCPercent)
+	}
+
+	return cfg, nil
+}
+
+func parsePercent(getenv func(string) string, name string, def uint32) (uint32, error) {
+	v := strings.TrimSpace(getenv(name))
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive integer", name, v)
+	}
+
+	return uint32(n), nil
+}
@@ -0,0 +1,117 @@
+package auto
+
+import (
+	"strings"
+	"testing"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig(envFunc(nil), 50, 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.disabled {
+		t.Fatal("expected enabled config")
+	}
+
+	if cfg.memLimitPercent != defaultMemLimitPercent {
+		t.Fatalf("expected memlimit percent %d, got %g", defaultMemLimitPercent, cfg.memLimitPercent)
+	}
+
+	if cfg.threshold != -1 {
+		t.Fatalf("expected derived threshold -1, got %d", cfg.threshold)
+	}
+
+	if cfg.minGCPercent != 50 || cfg.maxGCPercent != 500 {
+		t.Fatalf("expected bounds 50/500, got %d/%d", cfg.minGCPercent, cfg.maxGCPercent)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig(envFunc(map[string]string{
+		EnvMemLimitPercent: "80.5",
+		EnvThreshold:       "2GiB",
+		EnvMinGCPercent:    "25",
+		EnvMaxGCPercent:    " 300 ",
+		EnvDisable:         "false",
+	}), 50, 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.memLimitPercent != 80.5 {
+		t.Fatalf("expected memlimit percent 80.5, got %g", cfg.memLimitPercent)
+	}
+
+	if cfg.threshold != 2<<30 {
+		t.Fatalf("expected threshold %d, got %d", int64(2<<30), cfg.threshold)
+	}
+
+	if cfg.minGCPercent != 25 || cfg.maxGCPercent != 300 {
+		t.Fatalf("expected bounds 25/300, got %d/%d", cfg.minGCPercent, cfg.maxGCPercent)
+	}
+
+	want := "memlimit_percent=80.5 threshold=2147483648 min_gc_percent=25 max_gc_percent=300"
+	if got := cfg.String(); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestLoadConfigDisabled(t *testing.T) {
+	cfg, err := loadConfig(envFunc(map[string]string{
+		EnvDisable:         "1",
+		EnvMemLimitPercent: "invalid",
+	}), 50, 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cfg.disabled {
+		t.Fatal("expected disabled config")
+	}
+
+	if got := cfg.String(); got != "disabled" {
+		t.Fatalf("expected %q, got %q", "disabled", got)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"disable", map[string]string{EnvDisable: "maybe"}, EnvDisable},
+		{"percent not a number", map[string]string{EnvMemLimitPercent: "seventy"}, EnvMemLimitPercent},
+		{"percent zero", map[string]string{EnvMemLimitPercent: "0"}, EnvMemLimitPercent},
+		{"percent negative", map[string]string{EnvMemLimitPercent: "-5"}, EnvMemLimitPercent},
+		{"percent above 100", map[string]string{EnvMemLimitPercent: "101"}, EnvMemLimitPercent},
+		{"percent NaN", map[string]string{EnvMemLimitPercent: "NaN"}, EnvMemLimitPercent},
+		{"threshold unit", map[string]string{EnvThreshold: "2GB"}, EnvThreshold},
+		{"threshold zero", map[string]string{EnvThreshold: "0"}, EnvThreshold},
+		{"min not a number", map[string]string{EnvMinGCPercent: "low"}, EnvMinGCPercent},
+		{"min zero", map[string]string{EnvMinGCPercent: "0"}, EnvMinGCPercent},
+		{"max negative", map[string]string{EnvMaxGCPercent: "-1"}, EnvMaxGCPercent},
+		{"min above max", map[string]string{EnvMinGCPercent: "200", EnvMaxGCPercent: "100"}, EnvMinGCPercent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadConfig(envFunc(tt.env), 50, 500)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error mentioning %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -1,7 +1,19 @@
 // Package auto enables gctuner on import with reasonable defaults.
 //
-// Importing this package has side effects: it configures a memory limit and
-// starts the tuner during init. Defaults are captured at init time and can be
-// overridden by changing the exported variables before import.
-// See [MemLimitPercent], [MinGCPercent], and [MaxGCPercent].
+// Importing this package has side effects: during init, it sets the Go memory
+// limit to a percent of the effective memory limit and starts the tuner. The
+// configuration is read from environment variables:
+//
+//	GCTUNER_DISABLE           true to leave the tuner off
+//	GCTUNER_MEMLIMIT_PERCENT  percent of the effective memory limit, in (0, 100] (default 70)
+//	GCTUNER_THRESHOLD         explicit heap threshold, such as 2GiB (default: derived)
+//	GCTUNER_MIN_GC_PERCENT    minimum GC percent (default 50)
+//	GCTUNER_MAX_GC_PERCENT    maximum GC percent (default 500)
+//
+// Byte counts use the GOMEMLIMIT format. The resolved configuration is logged
+// once with the standard logger. If a variable is invalid, or the threshold
+// cannot be resolved, the error is logged and the tuner is not enabled.
+//
+// After init, [MemLimitPercent], [MinGCPercent], and [MaxGCPercent] hold the
+// resolved values.
 package auto
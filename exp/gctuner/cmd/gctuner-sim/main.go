@@ -0,0 +1,196 @@
+// Command gctuner-sim replays a recorded heap trace through the gctuner
+// strategies and a simple model of the Go pacer, to pick the threshold and
+// GC percent bounds offline.
+//
+// Usage:
+//
+//	gctuner-sim -limit 2GiB [flags] trace.csv
+//
+// The trace is a CSV or JSON file of samples with the time in seconds, the
+// allocation rate in bytes per second, and the live heap in bytes; see
+// testdata for examples. "-" reads it from standard input.
+//
+// For each strategy, gctuner-sim reports the number of simulated GC cycles,
+// the peak heap, the headroom left under the limit at the peak, the time
+// spent over the limit, and the GC percent. The "static" strategy is a fixed
+// GOGC without the tuner, as a baseline.
+//
+// The model is coarse: marking is instantaneous, the heap is compared with
+// the limit directly, and non-heap memory is ignored. Compare strategies and
+// settings with it rather than predict absolute numbers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "gctuner-sim:", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gctuner-sim", flag.ContinueOnError)
+	fs.SetOutput(stdout)
+
+	var (
+		limitFlag      = fs.String("limit", "", "memory `limit`, such as 2GiB (required)")
+		thresholdFlag  = fs.String("threshold", "", "heap `threshold` (default 70% of -limit)")
+		format         = fs.String("format", "", "trace `format`, csv or json (default from the file extension)")
+		strategies     = fs.String("strategies", "static,linear,ewma,pid", "comma-separated `list` of strategies")
+		gogc           = fs.Uint("gogc", 100, "GC percent of the static strategy")
+		minGCPercent   = fs.Uint("min", 50, "minimum GC `percent`")
+		maxGCPercent   = fs.Uint("max", 500, "maximum GC `percent`")
+		ewmaAlpha      = fs.Float64("ewma-alpha", gctuner.DefaultEWMAAlpha, "EWMA smoothing factor")
+		ewmaHysteresis = fs.Float64("ewma-hysteresis", 0, "EWMA hysteresis band")
+		pidTarget      = fs.Float64("pid-target", gctuner.DefaultPIDTarget, "PID target peak heap, as a fraction of the threshold")
+		memLimitPct    = fs.Float64("memlimit-percent", 0, "memory limit the tuner applies, as a `percent` of -limit (default: the threshold)")
+	)
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gctuner-sim -limit size [flags] trace")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("expected one trace file, got %d", fs.NArg())
+	}
+
+	if *limitFlag == "" {
+		return fmt.Errorf("-limit is required")
+	}
+
+	limit, err := parseBytes(*limitFlag)
+	if err != nil || limit == 0 {
+		return fmt.Errorf("invalid -limit %q", *limitFlag)
+	}
+
+	threshold := limit / 10 * 7
+	if *thresholdFlag != "" {
+		if threshold, err = parseBytes(*thresholdFlag); err != nil || threshold == 0 {
+			return fmt.Errorf("invalid -threshold %q", *thresholdFlag)
+		}
+	}
+
+	// The tuner would prefer GOMEMLIMIT to the threshold as the memory
+	// limit, but the one of this process is not the simulated one.
+	_ = os.Unsetenv("GOMEMLIMIT")
+
+	trace, err := openTrace(fs.Arg(0), *format, stdin)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(stdout, "limit %s, threshold %s, %d samples over %.1fs\n\n",
+		formatBytes(float64(limit)), formatBytes(float64(threshold)), len(trace), trace[len(trace)-1].Time-trace[0].Time)
+	fmt.Fprintln(tw, "STRATEGY\tGCS\tPEAK HEAP\tHEADROOM\tOVER LIMIT\tMEAN GC %\tFINAL GC %\t")
+
+	for _, name := range strings.Split(*strategies, ",") {
+		name = strings.TrimSpace(name)
+
+		var c controller
+		switch name {
+		case "static":
+			c = staticController(*gogc)
+		case "linear", "ewma", "pid":
+			var s gctuner.Strategy
+			switch name {
+			case "linear":
+				s = gctuner.LinearStrategy{}
+			case "ewma":
+				s = &gctuner.EWMAStrategy{Alpha: *ewmaAlpha, Hysteresis: *ewmaHysteresis}
+			case "pid":
+				s = &gctuner.PIDStrategy{Target: *pidTarget}
+			}
+
+			tc, err := newTunerController(s,
+				gctuner.WithThreshold(int64(threshold)),
+				gctuner.WithMinGCPercent(uint32(*minGCPercent)),
+				gctuner.WithMaxGCPercent(uint32(*maxGCPercent)),
+			)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			tc.mem.limit = limit
+			if *memLimitPct > 0 {
+				tc.tuner.SetMemLimitPercent(*memLimitPct)
+			}
+			c = tc
+		default:
+			return fmt.Errorf("unknown strategy %q", name)
+		}
+
+		r := simulate(name, c, trace, limit)
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%.1fs\t%.0f\t%d\t\n",
+			r.Name, r.GCs, formatBytes(float64(r.PeakHeap)), formatBytes(float64(r.Headroom)),
+			r.OverLimit, r.MeanGCPercent, r.FinalGCPercent)
+	}
+
+	return tw.Flush()
+}
+
+func openTrace(name, format string, stdin io.Reader) ([]sample, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".json":
+			format = "json"
+		default:
+			format = "csv"
+		}
+	}
+
+	if name == "-" {
+		return readTrace(stdin, format)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	trace, err := readTrace(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return trace, nil
+}
+
+// formatBytes formats n with a binary unit, such as "1.5GiB".
+func formatBytes(n float64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	i := 0
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%s%.0f%s", sign, n, units[i])
+	}
+
+	return fmt.Sprintf("%s%.1f%s", sign, n, units[i])
+}
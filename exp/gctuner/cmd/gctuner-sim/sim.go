@@ -0,0 +1,191 @@
+package main
+
+import (
+	"math"
+	"sync/atomic"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+// heapMinimum is the smallest heap goal of the Go pacer at GOGC=100.
+const heapMinimum = 4 << 20
+
+// controller decides the GC percent and memory limit after a GC cycle that
+// left live bytes on the heap. A limit of 0 means none.
+type controller interface {
+	afterGC(live uint64) (gcPercent uint32, limit uint64)
+}
+
+// staticController is a fixed GOGC without a memory limit, as a baseline.
+type staticController uint32
+
+func (c staticController) afterGC(uint64) (uint32, uint64) {
+	return uint32(c), 0
+}
+
+// tunerController runs a [gctuner.Tuner] on the simulated heap.
+type tunerController struct {
+	tuner *gctuner.Tuner
+	mem   *simMemory
+	rt    *simRuntime
+}
+
+func newTunerController(s gctuner.Strategy, opts ...gctuner.Option) (*tunerController, error) {
+	c := &tunerController{mem: &simMemory{}, rt: &simRuntime{}}
+
+	tn, err := gctuner.New(append([]gctuner.Option{
+		gctuner.WithMemorySource(c.mem),
+		gctuner.WithRuntime(c.rt),
+		gctuner.WithStrategy(s),
+		gctuner.WithTraceSize(0),
+	}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	c.tuner = tn
+
+	return c, nil
+}
+
+func (c *tunerController) afterGC(live uint64) (uint32, uint64) {
+	atomic.StoreUint64(&c.mem.inuse, live)
+	d := c.tuner.Tune()
+
+	return d.GCPercent, uint64(atomic.LoadInt64(&c.rt.memoryLimit))
+}
+
+// simMemory is the heap seen by the simulated tuner.
+type simMemory struct {
+	inuse uint64
+	limit uint64
+}
+
+func (m *simMemory) Heap() (uint64, uint64) {
+	return atomic.LoadUint64(&m.inuse), 0
+}
+
+func (m *simMemory) MemoryLimit() uint64 {
+	return atomic.LoadUint64(&m.limit)
+}
+
+// simRuntime records the settings applied by the simulated tuner.
+type simRuntime struct {
+	gcPercent   int64
+	memoryLimit int64
+}
+
+func (r *simRuntime) SetGCPercent(percent int) int {
+	return int(atomic.SwapInt64(&r.gcPercent, int64(percent)))
+}
+
+func (r *simRuntime) SetMemoryLimit(limit int64) int64 {
+	if limit < 0 {
+		return atomic.LoadInt64(&r.memoryLimit)
+	}
+
+	return atomic.SwapInt64(&r.memoryLimit, limit)
+}
+
+// result summarizes a simulation run.
+type result struct {
+	Name           string
+	GCs            int
+	PeakHeap       uint64
+	Headroom       int64   // limit - PeakHeap, negative if over the limit
+	OverLimit      float64 // seconds with the heap over the limit
+	FinalGCPercent uint32
+	MeanGCPercent  float64 // GC percent averaged over GC cycles
+}
+
+// simulate replays trace through a simple model of the Go pacer driven by c.
+//
+// The heap holds the live heap of the current sample plus the garbage
+// allocated since the last GC. A GC cycle runs when the heap reaches the
+// goal, live * (1 + gcPercent/100), at least heapMinimum * gcPercent/100,
+// and capped at the memory limit. It frees all garbage, then c picks the
+// next GC percent and limit. When the live heap alone reaches the goal, the
+// runtime would GC back to back; the model counts one cycle per sample and
+// keeps the heap at the live heap. Marking is instantaneous, so allocation during a cycle
+// is ignored.
+func simulate(name string, c controller, trace []sample, limit uint64) result {
+	res := result{Name: name}
+
+	live := float64(trace[0].LiveHeap)
+	heap := live
+	percent, memLimit := uint32(100), uint64(0) // runtime defaults before the first cycle
+	goal := heapGoal(live, percent, memLimit)
+	var percentSum float64
+
+	gc := func() {
+		res.GCs++
+		heap = live
+		percent, memLimit = c.afterGC(uint64(live))
+		percentSum += float64(percent)
+		goal = heapGoal(live, percent, memLimit)
+	}
+
+	peak := heap
+	for i := 0; i+1 < len(trace); i++ {
+		s := trace[i]
+		dt := trace[i+1].Time - s.Time
+
+		// Growth of the live heap is part of the allocation, and the rest
+		// turns into garbage. When the live heap shrinks, the heap stays
+		// the same: the difference turns into garbage.
+		next := float64(s.LiveHeap)
+		alloc := s.AllocRate * dt
+		if next > live {
+			alloc = math.Max(0, alloc-(next-live))
+			heap += next - live
+		}
+		live = next
+
+		stepPeak := heap
+		for heap+alloc >= goal {
+			if goal <= live {
+				// The live heap alone reaches the goal, so the runtime
+				// collects back to back and frees garbage as it comes.
+				gc()
+				alloc = 0
+
+				break
+			}
+
+			alloc -= goal - heap
+			heap = goal
+			stepPeak = math.Max(stepPeak, heap)
+			gc()
+		}
+
+		heap += alloc
+		stepPeak = math.Max(stepPeak, heap)
+
+		peak = math.Max(peak, stepPeak)
+		if limit > 0 && stepPeak > float64(limit) {
+			res.OverLimit += dt
+		}
+	}
+
+	res.PeakHeap = uint64(peak)
+	res.Headroom = int64(limit) - int64(res.PeakHeap)
+	res.FinalGCPercent = percent
+	if res.GCs > 0 {
+		res.MeanGCPercent = percentSum / float64(res.GCs)
+	}
+
+	return res
+}
+
+// heapGoal returns the heap size that triggers the next GC cycle.
+func heapGoal(live float64, percent uint32, limit uint64) float64 {
+	goal := live * (1 + float64(percent)/100)
+	if min := heapMinimum * float64(percent) / 100; goal < min {
+		goal = min
+	}
+
+	if limit > 0 && goal > float64(limit) {
+		goal = float64(limit)
+	}
+
+	return goal
+}
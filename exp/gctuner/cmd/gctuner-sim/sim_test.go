@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+const mib = 1 << 20
+
+func TestReadTraceCSV(t *testing.T) {
+	in := "live_heap,time,alloc_rate\n64MiB,0,1MiB/s\n128MiB, 1.5, 2097152\n"
+
+	got, err := readTrace(strings.NewReader(in), "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []sample{{0, mib, 64 * mib}, {1.5, 2 * mib, 128 * mib}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	// Without a header, the columns are in order.
+	got, err = readTrace(strings.NewReader("0,1,2\n1,3,4\n"), "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got[1] != (sample{1, 3, 4}) {
+		t.Fatalf("unexpected sample %+v", got[1])
+	}
+}
+
+func TestReadTraceJSON(t *testing.T) {
+	in := `[{"time": 0, "alloc_rate": "1MiB/s", "live_heap": 1024},
+		{"time": 2, "alloc_rate": 5e6, "live_heap": "1GiB"}]`
+
+	got, err := readTrace(strings.NewReader(in), "json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []sample{{0, mib, 1024}, {2, 5e6, 1 << 30}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestReadTraceErrors(t *testing.T) {
+	for _, tt := range []struct{ name, format, in string }{
+		{"format", "xml", ""},
+		{"short", "csv", "0,1,2\n"},
+		{"unordered", "csv", "1,1,1\n0,1,1\n"},
+		{"header", "csv", "time,rate,live\n0,1,1\n1,1,1\n"},
+		{"size", "csv", "0,1,2GB\n1,1,1\n"},
+		{"columns", "csv", "0,1\n1,1\n"},
+		{"json", "json", `[{"time": 0, "alloc_rate": true}]`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := readTrace(strings.NewReader(tt.in), tt.format); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestHeapGoal(t *testing.T) {
+	for _, tt := range []struct {
+		live    float64
+		percent uint32
+		limit   uint64
+		want    float64
+	}{
+		{100 * mib, 100, 0, 200 * mib},
+		{100 * mib, 50, 0, 150 * mib},
+		{100 * mib, 100, 150 * mib, 150 * mib},
+		{1 * mib, 100, 0, heapMinimum},
+		{1 * mib, 50, 0, heapMinimum / 2},
+	} {
+		if got := heapGoal(tt.live, tt.percent, tt.limit); got != tt.want {
+			t.Fatalf("heapGoal(%g, %d, %d) = %g, want %g", tt.live, tt.percent, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func steadyTrace(n int, rate float64, live uint64) []sample {
+	trace := make([]sample, n)
+	for i := range trace {
+		trace[i] = sample{Time: float64(i), AllocRate: rate, LiveHeap: live}
+	}
+
+	return trace
+}
+
+func TestSimulateStatic(t *testing.T) {
+	// 900MiB of garbage at GOGC=100 over a 100MiB live heap: a cycle every
+	// 100MiB.
+	r := simulate("static", staticController(100), steadyTrace(10, 100*mib, 100*mib), 1<<30)
+
+	if r.GCs != 9 || r.PeakHeap != 200*mib || r.FinalGCPercent != 100 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+
+	if r.Headroom != 1<<30-200*mib || r.OverLimit != 0 {
+		t.Fatalf("unexpected headroom: %+v", r)
+	}
+}
+
+func TestSimulateOverLimit(t *testing.T) {
+	r := simulate("static", staticController(100), steadyTrace(5, 100*mib, 400*mib), 512*mib)
+
+	if r.Headroom >= 0 || r.OverLimit != 3 {
+		t.Fatalf("expected the heap over the limit, got %+v", r)
+	}
+}
+
+func TestSimulateTuner(t *testing.T) {
+	if v, ok := os.LookupEnv("GOMEMLIMIT"); ok {
+		_ = os.Unsetenv("GOMEMLIMIT")
+		defer os.Setenv("GOMEMLIMIT", v)
+	}
+
+	c, err := newTunerController(gctuner.LinearStrategy{}, gctuner.WithThreshold(400*mib))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The tuner raises GOGC to 300 for a 100MiB live heap, so the heap
+	// peaks near the threshold with fewer cycles than GOGC=100.
+	r := simulate("linear", c, steadyTrace(10, 100*mib, 100*mib), 1<<30)
+	if r.FinalGCPercent != 300 || r.PeakHeap > 400*mib || r.GCs >= 9 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+
+	// A live heap over the threshold pins the goal to the memory limit the
+	// tuner applies, and the heap stays at the live heap.
+	c, err = newTunerController(gctuner.LinearStrategy{}, gctuner.WithThreshold(400*mib))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r = simulate("linear", c, steadyTrace(10, 100*mib, 500*mib), 1<<30)
+	if r.FinalGCPercent != 50 || r.OverLimit != 0 {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestRun(t *testing.T) {
+	for _, file := range []string{"testdata/bursty.csv", "testdata/steady.json"} {
+		var out bytes.Buffer
+		if err := run([]string{"-limit", "1GiB", file}, nil, &out); err != nil {
+			t.Fatalf("%s: unexpected error: %v", file, err)
+		}
+
+		for _, name := range []string{"static", "linear", "ewma", "pid"} {
+			if !strings.Contains(out.String(), name) {
+				t.Fatalf("%s: missing strategy %s in:\n%s", file, name, out.String())
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"-limit", "1GiB", "-format", "csv", "-strategies", "linear", "-"},
+		strings.NewReader("0,1MiB,1MiB\n1,1MiB,1MiB\n"), &out); err != nil {
+		t.Fatalf("unexpected error reading stdin: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"testdata/bursty.csv"},
+		{"-limit", "1GB", "testdata/bursty.csv"},
+		{"-limit", "1GiB", "-strategies", "magic", "testdata/bursty.csv"},
+		{"-limit", "1GiB", "-min", "0", "testdata/bursty.csv"},
+		{"-limit", "1GiB"},
+	} {
+		if err := run(args, nil, &bytes.Buffer{}); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, want := range map[float64]string{
+		512:           "512B",
+		1536:          "1.5KiB",
+		-3 * mib:      "-3.0MiB",
+		5 * (1 << 40): "5.0TiB",
+	} {
+		if got := formatBytes(n); got != want {
+			t.Fatalf("formatBytes(%g) = %q, want %q", n, got, want)
+		}
+	}
+}
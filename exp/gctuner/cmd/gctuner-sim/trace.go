@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go.dw1.io/x/exp/gctuner/internal/bytesize"
+)
+
+// sample is a point of a heap trace. It holds from Time until the next
+// sample.
+type sample struct {
+	Time      float64 // seconds since the start of the trace
+	AllocRate float64 // bytes allocated per second
+	LiveHeap  uint64  // bytes reachable after a GC
+}
+
+// readTrace reads a trace in the given format, "csv" or "json".
+//
+// A CSV trace has the columns time, alloc_rate, and live_heap, in that
+// order unless a header row names them. A JSON trace is an array of objects
+// with the same keys. Times are in seconds, and sizes are numbers of bytes or
+// GOMEMLIMIT-style byte counts such as "64MiB".
+func readTrace(r io.Reader, format string) ([]sample, error) {
+	var (
+		samples []sample
+		err     error
+	)
+
+	switch format {
+	case "csv":
+		samples, err = readCSV(r)
+	case "json":
+		samples, err = readJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", format)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(samples) < 2 {
+		return nil, fmt.Errorf("trace needs at least 2 samples, got %d", len(samples))
+	}
+
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Time <= samples[i-1].Time {
+			return nil, fmt.Errorf("sample %d: time %g is not after %g", i, samples[i].Time, samples[i-1].Time)
+		}
+	}
+
+	return samples, nil
+}
+
+func readCSV(r io.Reader) ([]sample, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := [3]int{0, 1, 2} // time, alloc_rate, live_heap
+	if len(records) > 0 {
+		if _, err := strconv.ParseFloat(records[0][0], 64); err != nil {
+			if cols, err = csvColumns(records[0]); err != nil {
+				return nil, err
+			}
+			records = records[1:]
+		}
+	}
+
+	samples := make([]sample, 0, len(records))
+	for i, rec := range records {
+		var s sample
+
+		field := func(col int) (string, error) {
+			if cols[col] >= len(rec) {
+				return "", fmt.Errorf("line %d: missing column %d", i+1, cols[col]+1)
+			}
+
+			return strings.TrimSpace(rec[cols[col]]), nil
+		}
+
+		v, err := field(0)
+		if err == nil {
+			s.Time, err = strconv.ParseFloat(v, 64)
+		}
+		if err == nil {
+			v, err = field(1)
+		}
+		if err == nil {
+			s.AllocRate, err = parseRate(v)
+		}
+		if err == nil {
+			v, err = field(2)
+		}
+		if err == nil {
+			s.LiveHeap, err = parseBytes(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv record %d: %v", i+1, err)
+		}
+
+		samples = append(samples, s)
+	}
+
+	return samples, nil
+}
+
+func csvColumns(header []string) ([3]int, error) {
+	cols := [3]int{-1, -1, -1}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "time":
+			cols[0] = i
+		case "alloc_rate":
+			cols[1] = i
+		case "live_heap":
+			cols[2] = i
+		}
+	}
+
+	for i, name := range []string{"time", "alloc_rate", "live_heap"} {
+		if cols[i] < 0 {
+			return cols, fmt.Errorf("csv header: missing column %q", name)
+		}
+	}
+
+	return cols, nil
+}
+
+// jsonValue is a number, or a string holding a number or a byte count.
+type jsonValue string
+
+func (v *jsonValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = jsonValue(s)
+
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return fmt.Errorf("expected a number or a string, got %s", b)
+	}
+	*v = jsonValue(n)
+
+	return nil
+}
+
+func readJSON(r io.Reader) ([]sample, error) {
+	var records []struct {
+		Time      float64   `json:"time"`
+		AllocRate jsonValue `json:"alloc_rate"`
+		LiveHeap  jsonValue `json:"live_heap"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+
+	samples := make([]sample, len(records))
+	for i, rec := range records {
+		rate, err := parseRate(string(rec.AllocRate))
+		if err != nil {
+			return nil, fmt.Errorf("json record %d: %v", i, err)
+		}
+
+		live, err := parseBytes(string(rec.LiveHeap))
+		if err != nil {
+			return nil, fmt.Errorf("json record %d: %v", i, err)
+		}
+
+		samples[i] = sample{Time: rec.Time, AllocRate: rate, LiveHeap: live}
+	}
+
+	return samples, nil
+}
+
+// parseBytes parses a number of bytes or a byte count such as "64MiB".
+func parseBytes(s string) (uint64, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f >= 0 {
+		return uint64(f), nil
+	}
+
+	n, ok := bytesize.Parse(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid byte count %q", s)
+	}
+
+	return uint64(n), nil
+}
+
+// parseRate parses an allocation rate in bytes per second, with an optional
+// "/s" suffix, such as "128MiB/s".
+func parseRate(s string) (float64, error) {
+	n, err := parseBytes(strings.TrimSuffix(s, "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid allocation rate %q", s)
+	}
+
+	return float64(n), nil
+}
@@ -0,0 +1,37 @@
+// nolint
+//go:build go1.20
+// +build go1.20
+
+package gctuner
+
+import (
+	"runtime/metrics"
+	"sync"
+)
+
+var (
+	cpuSamplesMu sync.Mutex
+	cpuSamples   = newSamples([]string{
+		"/cpu/classes/gc/total:cpu-seconds",
+		"/cpu/classes/total:cpu-seconds",
+	})
+)
+
+// readCPUTime returns the CPU time spent on GC and available to the process
+// since it started, in seconds, as estimated by the runtime.
+func readCPUTime() (gc, total float64) {
+	cpuSamplesMu.Lock()
+	defer cpuSamplesMu.Unlock()
+
+	metrics.Read(cpuSamples)
+
+	return sampleFloat64(cpuSamples[0]), sampleFloat64(cpuSamples[1])
+}
+
+func sampleFloat64(s metrics.Sample) float64 {
+	if s.Value.Kind() != metrics.KindFloat64 {
+		return 0
+	}
+
+	return s.Value.Float64()
+}
@@ -0,0 +1,19 @@
+// nolint
+//go:build go1.20
+// +build go1.20
+
+package gctuner
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestReadCPUTime(t *testing.T) {
+	runtime.GC()
+
+	gc, total := readCPUTime()
+	if gc <= 0 || total < gc {
+		t.Fatalf("expected 0 < gc <= total, got gc %g, total %g", gc, total)
+	}
+}
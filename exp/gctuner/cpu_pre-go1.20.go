@@ -0,0 +1,11 @@
+// nolint
+//go:build !go1.20
+// +build !go1.20
+
+package gctuner
+
+// readCPUTime returns 0, 0: the CPU classes of runtime/metrics are only
+// available on Go 1.20 and newer.
+func readCPUTime() (gc, total float64) {
+	return 0, 0
+}
@@ -0,0 +1,146 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+// Path is the path [Handler] is registered at on [http.DefaultServeMux].
+const Path = "/debug/gctuner"
+
+func init() {
+	http.Handle(Path, Handler())
+}
+
+// Handler returns an http.Handler that serves the trace of the default
+// tuner.
+func Handler() http.Handler {
+	return handler(gctuner.Stats, gctuner.Trace)
+}
+
+// TunerHandler returns an http.Handler that serves the trace of t.
+func TunerHandler(t *gctuner.Tuner) http.Handler {
+	return handler(t.Stats, t.Trace)
+}
+
+func handler(stats func() gctuner.Snapshot, trace func() []gctuner.Decision) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+
+		switch format := r.FormValue("format"); format {
+		case "json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = writeJSON(w, stats(), trace())
+		case "", "text":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			_ = writeText(w, stats(), trace())
+		default:
+			http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+		}
+	})
+}
+
+// record is the JSON form of a [gctuner.Decision].
+type record struct {
+	Time          time.Time `json:"time"`
+	HeapInuse     uint64    `json:"heap_inuse_bytes"`
+	HeapGoal      uint64    `json:"heap_goal_bytes"`
+	NonHeap       uint64    `json:"nonheap_bytes"`
+	Threshold     uint64    `json:"threshold_bytes"`
+	MemoryLimit   uint64    `json:"memory_limit_bytes"`
+	PrevGCPercent uint32    `json:"prev_gc_percent"`
+	GCPercent     uint32    `json:"gc_percent"`
+	MinGCPercent  uint32    `json:"min_gc_percent"`
+	MaxGCPercent  uint32    `json:"max_gc_percent"`
+	Clamped       string    `json:"clamped,omitempty"`
+	PressureSome  float64   `json:"pressure_some"`
+	PressureFull  float64   `json:"pressure_full"`
+	PressureLevel string    `json:"pressure_level"`
+}
+
+type status struct {
+	Enabled       bool     `json:"enabled"`
+	Threshold     uint64   `json:"threshold_bytes"`
+	GCPercent     uint32   `json:"gc_percent"`
+	MinGCPercent  uint32   `json:"min_gc_percent"`
+	MaxGCPercent  uint32   `json:"max_gc_percent"`
+	Tunings       uint64   `json:"tunings"`
+	PressureLevel string   `json:"pressure_level"`
+	Trace         []record `json:"trace"`
+}
+
+func writeJSON(w io.Writer, s gctuner.Snapshot, trace []gctuner.Decision) error {
+	out := status{
+		Enabled:       s.Enabled,
+		Threshold:     s.Threshold,
+		GCPercent:     s.GCPercent,
+		MinGCPercent:  s.MinGCPercent,
+		MaxGCPercent:  s.MaxGCPercent,
+		Tunings:       s.Tunings,
+		PressureLevel: s.PressureLevel.String(),
+		Trace:         make([]record, len(trace)),
+	}
+
+	for i, d := range trace {
+		out.Trace[i] = record{
+			Time:          d.Time,
+			HeapInuse:     d.HeapInuse,
+			HeapGoal:      d.HeapGoal,
+			NonHeap:       d.NonHeap,
+			Threshold:     d.Threshold,
+			MemoryLimit:   d.MemoryLimit,
+			PrevGCPercent: d.PrevGCPercent,
+			GCPercent:     d.GCPercent,
+			MinGCPercent:  d.MinGCPercent,
+			MaxGCPercent:  d.MaxGCPercent,
+			Clamped:       clamped(d),
+			PressureSome:  d.PressureSome,
+			PressureFull:  d.PressureFull,
+			PressureLevel: d.PressureLevel.String(),
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(out)
+}
+
+func writeText(w io.Writer, s gctuner.Snapshot, trace []gctuner.Decision) error {
+	fmt.Fprintf(w, "gctuner: enabled=%t threshold=%d gc_percent=%d bounds=%d-%d tunings=%d pressure=%s\n",
+		s.Enabled, s.Threshold, s.GCPercent, s.MinGCPercent, s.MaxGCPercent, s.Tunings, s.PressureLevel)
+	fmt.Fprintf(w, "trace: %d decisions, oldest first\n\n", len(trace))
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "TIME\tHEAP INUSE\tNON-HEAP\tTHRESHOLD\tMEMORY LIMIT\tPREV %\tGC %\tCLAMPED\tPRESSURE\t")
+	for _, d := range trace {
+		c := clamped(d)
+		if c == "" {
+			c = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t\n",
+			d.Time.Format("2006-01-02T15:04:05.000Z07:00"), d.HeapInuse, d.NonHeap, d.Threshold, d.MemoryLimit,
+			d.PrevGCPercent, d.GCPercent, c, d.PressureLevel)
+	}
+
+	return tw.Flush()
+}
+
+// clamped returns the bound the GC percent of d was clamped to, if any.
+func clamped(d gctuner.Decision) string {
+	switch {
+	case d.ClampedToMin:
+		return "min"
+	case d.ClampedToMax:
+		return "max"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,125 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+type fakeMemory struct{ inuse uint64 }
+
+func (m *fakeMemory) Heap() (uint64, uint64) { return m.inuse, 0 }
+func (m *fakeMemory) MemoryLimit() uint64    { return 0 }
+
+type fakeRuntime struct{}
+
+func (fakeRuntime) SetGCPercent(int) int       { return 100 }
+func (fakeRuntime) SetMemoryLimit(int64) int64 { return 0 }
+
+func newTuner(t *testing.T) *gctuner.Tuner {
+	t.Helper()
+
+	mem := &fakeMemory{}
+	tn, err := gctuner.New(
+		gctuner.WithMemorySource(mem),
+		gctuner.WithRuntime(fakeRuntime{}),
+		gctuner.WithThreshold(4<<30),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+
+	for _, inuse := range []uint64{1 << 30, 4 << 30} {
+		mem.inuse = inuse
+		tn.Tune()
+	}
+
+	return tn
+}
+
+func serve(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	return rec
+}
+
+func TestTunerHandlerText(t *testing.T) {
+	tn := newTuner(t)
+	defer tn.Close()
+
+	rec := serve(t, TunerHandler(tn), Path)
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	body := rec.Body.String()
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("expected header, table header, and 2 rows:\n%s", body)
+	}
+
+	if !strings.Contains(lines[0], "threshold=4294967296") || !strings.Contains(lines[1], "2 decisions") {
+		t.Fatalf("unexpected summary:\n%s", body)
+	}
+
+	if !strings.Contains(lines[3], "HEAP INUSE") {
+		t.Fatalf("missing table header:\n%s", body)
+	}
+
+	if f := strings.Fields(lines[4]); f[1] != "1073741824" || f[6] != "300" || f[7] != "-" {
+		t.Fatalf("unexpected first row %q", lines[4])
+	}
+
+	if f := strings.Fields(lines[5]); f[1] != "4294967296" || f[6] != "50" || f[7] != "min" {
+		t.Fatalf("unexpected second row %q", lines[5])
+	}
+}
+
+func TestTunerHandlerJSON(t *testing.T) {
+	tn := newTuner(t)
+	defer tn.Close()
+
+	rec := serve(t, TunerHandler(tn), Path+"?format=json")
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	var got status
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error decoding JSON: %v", err)
+	}
+
+	if got.Threshold != 4<<30 || got.Tunings != 2 || len(got.Trace) != 2 {
+		t.Fatalf("unexpected status: %+v", got)
+	}
+
+	r := got.Trace[1]
+	if r.HeapInuse != 4<<30 || r.GCPercent != 50 || r.Clamped != "min" || r.PressureLevel != "none" {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+
+	if time.Since(r.Time) > time.Minute {
+		t.Fatalf("unexpected record time %s", r.Time)
+	}
+}
+
+func TestHandlerUnknownFormat(t *testing.T) {
+	rec := serve(t, Handler(), Path+"?format=xml")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	if _, pattern := http.DefaultServeMux.Handler(httptest.NewRequest(http.MethodGet, Path, nil)); pattern != Path {
+		t.Fatalf("expected %s to be registered, got %q", Path, pattern)
+	}
+}
@@ -0,0 +1,13 @@
+// Package debug serves the recent tuning decisions of gctuner over HTTP, in
+// the spirit of [net/http/pprof].
+//
+// Importing the package registers [Handler] at /debug/gctuner on
+// [http.DefaultServeMux]:
+//
+//	import _ "go.dw1.io/x/exp/gctuner/debug"
+//
+// The endpoint renders [gctuner.Trace] as a plain-text table, one GC cycle
+// per line, oldest first. With ?format=json, it renders a JSON object with
+// the current [gctuner.Stats] and the trace instead. To serve a [gctuner.Tuner]
+// other than the default one, or on another mux, use [TunerHandler].
+package debug
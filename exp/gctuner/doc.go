@@ -16,13 +16,17 @@
 //
 // A threshold can be set explicitly (bytes), or derived from the effective
 // memory limit via [Enable](-1). A common choice is 70% of the effective memory
-// limit. The effective memory limit is determined as follows:
+// limit. The effective memory limit is the lowest of:
 //
-//	Go < 1.19: detected host/cgroup memory limit
-//	Go >= 1.19: GOMEMLIMIT (if set) overrides host/cgroup detection
+//	GOMEMLIMIT (Go >= 1.19, if set)
+//	MEMORY_LIMIT, a byte count or a file containing one (Kubernetes downward API)
+//	the cgroup v1 or v2 memory limit (Linux)
+//	the physical memory
+//	the RLIMIT_AS and RLIMIT_DATA soft limits (Unix)
+//	the function set with [WithLimitSource]
 //
-// On Linux, memory limit detection is cgroup-aware. On non-Linux platforms
-// it returns 0 (unknown).
+// [DetectLimit] reports the effective memory limit and the source it was read
+// from.
 //
 // Memory limits on Go 1.19+
 //
@@ -33,9 +37,33 @@
 //  2. GOMEMLIMIT (if set)
 //  3. threshold
 //
+// # Non-heap memory
+//
+// The threshold bounds the Go heap only. Memory held elsewhere, such as mmap'd
+// files or cgo allocations, can be reported with [RegisterAccountant]; it is
+// subtracted from the threshold and the memory limit on every tuning step.
+// [WithRuntimeAccountant] does the same for runtime non-heap memory, such as
+// goroutine stacks.
+//
+// # Emergency mode
+//
+// At the minimum GC percent, the tuner cannot do more on its own. With
+// [WithEmergency], it tracks a [PressureLevel] as the heap in use stays above
+// the threshold or crosses a second watermark, notifies the [OnPressure]
+// callbacks so applications can shed load, and returns memory to the OS.
+//
+// # Tuner instances
+//
+// The package-level functions configure a default [Tuner]. [New] creates
+// independent instances, which read memory from a [MemorySource] and apply
+// decisions to a [Runtime], so tuning can be embedded and unit-tested.
+//
 // # Disabling
 //
-// [Enable](0) disables the tuner. [Enable](-1) derives the threshold from the
-// effective memory limit (or [SetMemLimitPercent] override if present). If the
-// limit cannot be determined, [Enable](-1) returns an error.
+// [Enable](0) or [Disable] disables the tuner and restores the GC percent and
+// memory limit that were in effect before it first changed them. [Reset] also
+// returns the configuration to the defaults. [Enable](-1) derives the
+// threshold from the effective memory limit (or [SetMemLimitPercent] override
+// if present). If the limit cannot be determined, [Enable](-1) returns an
+// error.
 package gctuner
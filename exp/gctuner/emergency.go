@@ -0,0 +1,255 @@
+package gctuner
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// PressureLevel is how close the heap is to running out of room, as tracked
+// by [WithEmergency].
+type PressureLevel uint32
+
+const (
+	// PressureNone means the heap in use is below the threshold.
+	PressureNone PressureLevel = iota
+	// PressureHigh means the heap in use has stayed at or above the
+	// threshold for [Emergency].Cycles consecutive GC cycles. The GC percent
+	// is at its minimum and cannot help any further.
+	PressureHigh
+	// PressureEmergency means the heap in use has crossed the
+	// [Emergency].Watermark. Memory is returned to the OS and applications
+	// should shed load.
+	PressureEmergency
+)
+
+// String returns the name of the level.
+func (l PressureLevel) String() string {
+	switch l {
+	case PressureNone:
+		return "none"
+	case PressureHigh:
+		return "high"
+	case PressureEmergency:
+		return "emergency"
+	default:
+		return fmt.Sprintf("PressureLevel(%d)", uint32(l))
+	}
+}
+
+// Defaults used by Emergency when its fields are 0.
+const (
+	DefaultEmergencyWatermark  = 1.2
+	DefaultEmergencyCycles     = 3
+	DefaultEmergencyHysteresis = 0.1
+)
+
+// Emergency configures the emergency tier of a tuner, see [WithEmergency].
+//
+// The zero value is usable.
+type Emergency struct {
+	// Watermark is the heap in use, as a multiple of the threshold, at
+	// which the tuner enters [PressureEmergency]. It must be at least 1. 0
+	// means DefaultEmergencyWatermark.
+	Watermark float64
+	// Cycles is the number of consecutive GC cycles the heap in use must
+	// stay at or above the threshold to enter [PressureHigh]. 0 means
+	// DefaultEmergencyCycles.
+	Cycles int
+	// Hysteresis is the relative margin, in [0, 1), the heap in use must
+	// fall below a level's entry point before the level is left, such as
+	// 0.1 for 10%. 0 means DefaultEmergencyHysteresis.
+	Hysteresis float64
+}
+
+func (e *Emergency) validate() error {
+	if e.Watermark != 0 && (e.Watermark < 1 || math.IsNaN(e.Watermark) || math.IsInf(e.Watermark, 0)) {
+		return fmt.Errorf("invalid emergency watermark: %g", e.Watermark)
+	}
+
+	if e.Cycles < 0 {
+		return fmt.Errorf("invalid emergency cycles: %d", e.Cycles)
+	}
+
+	if e.Hysteresis < 0 || e.Hysteresis >= 1 || math.IsNaN(e.Hysteresis) {
+		return fmt.Errorf("invalid emergency hysteresis: %g", e.Hysteresis)
+	}
+
+	return nil
+}
+
+// OnPressure registers fn with the default tuner. See [Tuner.OnPressure].
+func OnPressure(fn func(PressureLevel)) {
+	std.OnPressure(fn)
+}
+
+// GetPressureLevel returns the pressure level of the default tuner, or
+// [PressureNone] if it is disabled.
+func GetPressureLevel() PressureLevel {
+	if !std.Running() {
+		return PressureNone
+	}
+
+	return std.PressureLevel()
+}
+
+// OnPressure registers fn to be called with the new level whenever the
+// pressure level tracked by [WithEmergency] changes, including when it
+// recovers to [PressureNone]. A nil fn is ignored.
+//
+// Callbacks run on the runtime finalizer goroutine after a GC cycle, in
+// registration order, before memory is returned to the OS. When the tuner
+// stops or the emergency tier is changed while the level is raised, they
+// are called with [PressureNone] from [Tuner.Stop] or [Tuner.Update]. They
+// should drop caches or flip a load-shedding flag quickly, must not block,
+// and must not call methods of the tuner.
+func (t *Tuner) OnPressure(fn func(PressureLevel)) {
+	if fn == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := t.getPressureCallbacks()
+	next := make(pressureCallbacks, 0, len(prev)+1)
+	next = append(next, prev...)
+	next = append(next, fn)
+	t.pressureCallbacks.Store(next)
+}
+
+// PressureLevel returns the current pressure level. It is always
+// [PressureNone] unless [WithEmergency] is set.
+func (t *Tuner) PressureLevel() PressureLevel {
+	return PressureLevel(atomic.LoadUint32(&t.level))
+}
+
+type pressureCallbacks []func(PressureLevel)
+
+func (t *Tuner) getPressureCallbacks() pressureCallbacks {
+	fns, _ := t.pressureCallbacks.Load().(pressureCallbacks)
+
+	return fns
+}
+
+// setPressureLevel records level and, if it changed from prev, notifies the
+// callbacks and returns memory to the OS on entering [PressureEmergency].
+func (t *Tuner) setPressureLevel(level, prev PressureLevel) {
+	atomic.StoreUint32(&t.level, uint32(level))
+
+	if level == prev {
+		return
+	}
+
+	for _, fn := range t.getPressureCallbacks() {
+		fn(level)
+	}
+
+	if level == PressureEmergency {
+		if r, ok := t.getRuntime().(osMemoryFreer); ok {
+			r.FreeOSMemory()
+		}
+	}
+}
+
+// clearPressureLevel resets the level to [PressureNone], notifying the
+// callbacks if it was raised. It is called when the tuner stops or the
+// emergency tier changes, so load shedding does not outlive them.
+func (t *Tuner) clearPressureLevel() {
+	prev := PressureLevel(atomic.SwapUint32(&t.level, uint32(PressureNone)))
+	if prev == PressureNone {
+		return
+	}
+
+	for _, fn := range t.getPressureCallbacks() {
+		fn(PressureNone)
+	}
+}
+
+// osMemoryFreer is implemented by a [Runtime] that can return memory to the
+// OS, such as with [debug.FreeOSMemory].
+type osMemoryFreer interface {
+	FreeOSMemory()
+}
+
+// emergencyMonitor tracks the pressure level across GC cycles.
+type emergencyMonitor struct {
+	watermark  float64
+	cycles     int
+	hysteresis float64
+
+	mu    sync.Mutex
+	above int // consecutive cycles at or above the threshold
+	level PressureLevel
+}
+
+func newEmergencyMonitor(e Emergency) *emergencyMonitor {
+	m := &emergencyMonitor{
+		watermark:  e.Watermark,
+		cycles:     e.Cycles,
+		hysteresis: e.Hysteresis,
+	}
+
+	if m.watermark == 0 {
+		m.watermark = DefaultEmergencyWatermark
+	}
+
+	if m.cycles == 0 {
+		m.cycles = DefaultEmergencyCycles
+	}
+
+	if m.hysteresis == 0 {
+		m.hysteresis = DefaultEmergencyHysteresis
+	}
+
+	return m
+}
+
+// observe records a GC cycle and returns the new and the previous level.
+//
+// A level is entered when the heap in use reaches its entry point: the
+// threshold for Cycles consecutive cycles for PressureHigh, and Watermark
+// times the threshold for PressureEmergency. It is left once the heap in use
+// falls below (1 - Hysteresis) times that entry point.
+func (m *emergencyMonitor) observe(inuse, threshold uint64) (level, prev PressureLevel) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prev = m.level
+	if threshold == 0 {
+		return prev, prev
+	}
+
+	heap := float64(inuse)
+	high := float64(threshold)
+	emergency := high * m.watermark
+	keep := 1 - m.hysteresis
+
+	if heap >= high {
+		m.above++
+	} else {
+		m.above = 0
+	}
+
+	switch {
+	case heap >= emergency,
+		prev == PressureEmergency && heap >= emergency*keep:
+		m.level = PressureEmergency
+	case m.above >= m.cycles,
+		prev != PressureNone && heap >= high*keep:
+		m.level = PressureHigh
+	default:
+		m.level = PressureNone
+	}
+
+	return m.level, prev
+}
+
+func (m *emergencyMonitor) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.above = 0
+	m.level = PressureNone
+}
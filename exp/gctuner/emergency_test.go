@@ -0,0 +1,164 @@
+package gctuner
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// freeingRuntime is a fakeRuntime that counts FreeOSMemory calls.
+type freeingRuntime struct {
+	fakeRuntime
+	frees int64
+}
+
+func (r *freeingRuntime) FreeOSMemory() {
+	atomic.AddInt64(&r.frees, 1)
+}
+
+func TestPressureLevelString(t *testing.T) {
+	for level, want := range map[PressureLevel]string{
+		PressureNone:      "none",
+		PressureHigh:      "high",
+		PressureEmergency: "emergency",
+		PressureLevel(7):  "PressureLevel(7)",
+	} {
+		if got := level.String(); got != want {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestEmergencyMonitor(t *testing.T) {
+	m := newEmergencyMonitor(Emergency{Watermark: 1.5, Cycles: 2, Hysteresis: 0.2})
+
+	const threshold = 1000
+
+	steps := []struct {
+		inuse uint64
+		want  PressureLevel
+	}{
+		{500, PressureNone},
+		{1000, PressureNone},      // first cycle at the threshold
+		{1100, PressureHigh},      // second consecutive cycle
+		{900, PressureHigh},       // within the hysteresis band
+		{700, PressureNone},       // below 80% of the threshold
+		{1000, PressureNone},      // the cycle count restarted
+		{1500, PressureEmergency}, // the watermark is entered at once
+		{1300, PressureEmergency}, // within the hysteresis band
+		{1100, PressureHigh},      // below 80% of the watermark
+		{850, PressureHigh},
+		{790, PressureNone},
+	}
+
+	for i, step := range steps {
+		if got, _ := m.observe(step.inuse, threshold); got != step.want {
+			t.Fatalf("step %d: inuse %d: expected level %s, got %s", i, step.inuse, step.want, got)
+		}
+	}
+
+	m.reset()
+	if got, prev := m.observe(1000, threshold); got != PressureNone || prev != PressureNone {
+		t.Fatalf("expected reset level, got %s (prev %s)", got, prev)
+	}
+}
+
+func TestEmergencyDefaults(t *testing.T) {
+	m := newEmergencyMonitor(Emergency{})
+	if m.watermark != DefaultEmergencyWatermark || m.cycles != DefaultEmergencyCycles || m.hysteresis != DefaultEmergencyHysteresis {
+		t.Fatalf("unexpected defaults: %+v", m)
+	}
+}
+
+func TestWithEmergencyValidation(t *testing.T) {
+	for _, e := range []Emergency{
+		{Watermark: 0.9},
+		{Watermark: -1},
+		{Cycles: -1},
+		{Hysteresis: 1},
+		{Hysteresis: -0.1},
+	} {
+		e := e
+		if _, err := New(WithThreshold(1<<30), WithEmergency(&e)); err == nil {
+			t.Fatalf("expected error for %+v", e)
+		}
+	}
+}
+
+func TestTunerEmergency(t *testing.T) {
+	mem := &fakeMemory{inuse: 1 << 30}
+	rt := &freeingRuntime{fakeRuntime: fakeRuntime{gcPercent: 100}}
+
+	tn, err := New(
+		WithMemorySource(mem),
+		WithRuntime(rt),
+		WithThreshold(4<<30),
+		WithEmergency(&Emergency{Watermark: 1.25, Cycles: 2}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	var (
+		levels []PressureLevel
+		frees  []int64
+	)
+	tn.OnPressure(func(level PressureLevel) {
+		levels = append(levels, level)
+		frees = append(frees, atomic.LoadInt64(&rt.frees))
+	})
+	tn.OnPressure(nil)
+
+	for _, inuse := range []uint64{1 << 30, 4 << 30, 4 << 30, 5 << 30, 5 << 30, 1 << 30} {
+		atomic.StoreUint64(&mem.inuse, inuse)
+		d := tn.Tune()
+		if d.PressureLevel != tn.PressureLevel() {
+			t.Fatalf("expected decision level %s, got %s", tn.PressureLevel(), d.PressureLevel)
+		}
+	}
+
+	want := []PressureLevel{PressureHigh, PressureEmergency, PressureNone}
+	if !reflect.DeepEqual(levels, want) {
+		t.Fatalf("expected levels %v, got %v", want, levels)
+	}
+
+	// Callbacks run before memory is returned to the OS.
+	if got := atomic.LoadInt64(&rt.frees); got != 1 || !reflect.DeepEqual(frees, []int64{0, 0, 1}) {
+		t.Fatalf("expected 1 FreeOSMemory call after the callbacks, got %d (%v)", got, frees)
+	}
+
+	if s := tn.Stats(); s.Emergencies != 1 || s.PressureLevel != PressureNone {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	// Turning the tier off resets the level.
+	atomic.StoreUint64(&mem.inuse, 5<<30)
+	tn.Tune()
+	if tn.PressureLevel() != PressureEmergency {
+		t.Fatalf("expected emergency level, got %s", tn.PressureLevel())
+	}
+
+	if err := tn.Update(WithEmergency(nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tn.PressureLevel() != PressureNone {
+		t.Fatalf("expected level reset, got %s", tn.PressureLevel())
+	}
+
+	want = []PressureLevel{PressureHigh, PressureEmergency, PressureNone, PressureEmergency, PressureNone}
+	if !reflect.DeepEqual(levels, want) {
+		t.Fatalf("expected levels %v, got %v", want, levels)
+	}
+}
+
+func TestGetPressureLevelWhenDisabled(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	atomic.StoreUint32(&std.level, uint32(PressureEmergency))
+	if got := GetPressureLevel(); got != PressureNone {
+		t.Fatalf("expected level none when disabled, got %s", got)
+	}
+}
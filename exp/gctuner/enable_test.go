@@ -10,27 +10,19 @@ import (
 func saveAndResetState(t *testing.T) func() {
 	t.Helper()
 
-	oldMin := atomic.LoadUint32(&minGCPercent)
-	oldMax := atomic.LoadUint32(&maxGCPercent)
+	oldStd := std
 	oldDefault := defaultGCPercent
-	oldGlobal := globalTuner
-	oldOverride := atomic.LoadUint64(&memLimitOverride)
-	oldOverrideSet := atomic.LoadUint32(&memLimitOverrideSet)
 	oldGOGC := os.Getenv("GOGC")
 	oldGOMEMLIMIT := os.Getenv("GOMEMLIMIT")
 
+	std = newTunerInstance()
+
 	return func() {
-		if globalTuner != nil {
-			globalTuner.stop()
-		}
+		_ = std.Close()
 
-		globalTuner = oldGlobal
-		atomic.StoreUint32(&minGCPercent, oldMin)
-		atomic.StoreUint32(&maxGCPercent, oldMax)
+		std = oldStd
 		defaultGCPercent = oldDefault
 		defaultGCOnce = sync.Once{}
-		atomic.StoreUint64(&memLimitOverride, oldOverride)
-		atomic.StoreUint32(&memLimitOverrideSet, oldOverrideSet)
 
 		if oldGOGC == "" {
 			_ = os.Unsetenv("GOGC")
@@ -89,22 +81,22 @@ func TestValidateGCPercentRange(t *testing.T) {
 	}
 }
 
-func TestNormalizeThreshold(t *testing.T) {
+func TestResolveThreshold(t *testing.T) {
 	cleanup := saveAndResetState(t)
 	defer cleanup()
 
-	if got, disable, err := normalizeThreshold(0); err != nil || !disable || got != 0 {
-		t.Fatalf("expected disable with zero threshold, got %d disable=%v err=%v", got, disable, err)
+	if got, err := std.resolveThreshold(0); err != nil || got != 0 {
+		t.Fatalf("expected zero threshold, got %d err=%v", got, err)
 	}
 
-	atomic.StoreUint64(&memLimitOverride, 12345)
-	atomic.StoreUint32(&memLimitOverrideSet, 1)
-	if got, disable, err := normalizeThreshold(-1); err != nil || disable || got != 12345 {
-		t.Fatalf("expected override threshold 12345, got %d disable=%v err=%v", got, disable, err)
+	atomic.StoreUint64(&std.memLimitOverride, 12345)
+	atomic.StoreUint32(&std.memLimitOverrideSet, 1)
+	if got, err := std.resolveThreshold(-1); err != nil || got != 12345 {
+		t.Fatalf("expected override threshold 12345, got %d err=%v", got, err)
 	}
 
-	if got, disable, err := normalizeThreshold(2048); err != nil || disable || got != 2048 {
-		t.Fatalf("expected threshold 2048, got %d disable=%v err=%v", got, disable, err)
+	if got, err := std.resolveThreshold(2048); err != nil || got != 2048 {
+		t.Fatalf("expected threshold 2048, got %d err=%v", got, err)
 	}
 }
 
@@ -112,28 +104,28 @@ func TestEnableDisableAndUpdate(t *testing.T) {
 	cleanup := saveAndResetState(t)
 	defer cleanup()
 
-	atomic.StoreUint64(&memLimitOverride, 4096)
-	atomic.StoreUint32(&memLimitOverrideSet, 1)
+	atomic.StoreUint64(&std.memLimitOverride, 4096)
+	atomic.StoreUint32(&std.memLimitOverrideSet, 1)
 
 	if err := Enable(-1); err != nil {
 		t.Fatalf("expected Enable(-1) to succeed, got %v", err)
 	}
-	if globalTuner == nil || globalTuner.getThreshold() != 4096 {
-		t.Fatalf("expected global tuner threshold 4096, got %v", globalTuner)
+	if !std.Running() || std.getThreshold() != 4096 {
+		t.Fatalf("expected running default tuner with threshold 4096, got %d", std.getThreshold())
 	}
 
 	if err := Enable(8192); err != nil {
 		t.Fatalf("expected Enable(8192) to succeed, got %v", err)
 	}
-	if globalTuner == nil || globalTuner.getThreshold() != 8192 {
-		t.Fatalf("expected global tuner threshold 8192, got %v", globalTuner)
+	if !std.Running() || std.getThreshold() != 8192 {
+		t.Fatalf("expected running default tuner with threshold 8192, got %d", std.getThreshold())
 	}
 
 	if err := Enable(0); err != nil {
 		t.Fatalf("expected Enable(0) to succeed, got %v", err)
 	}
-	if globalTuner != nil {
-		t.Fatalf("expected global tuner to be nil after disable")
+	if std.Running() {
+		t.Fatalf("expected default tuner to be stopped after disable")
 	}
 }
 
@@ -7,10 +7,18 @@ import (
 
 type finalizerCallback func()
 
+// finalizer states. A stopped finalizer can resume until its chain observes
+// the stop after the next GC cycle and ends.
+const (
+	finalizerRunning int32 = iota
+	finalizerStopped
+	finalizerDone
+)
+
 type finalizer struct {
 	ref      *finalizerRef
 	callback finalizerCallback
-	stopped  int32
+	state    int32
 }
 
 type finalizerRef struct {
@@ -18,8 +26,8 @@ type finalizerRef struct {
 }
 
 func finalizerHandler(f *finalizerRef) {
-	// stop calling callback
-	if atomic.LoadInt32(&f.parent.stopped) > 0 {
+	// stop calling callback and end the chain
+	if atomic.CompareAndSwapInt32(&f.parent.state, finalizerStopped, finalizerDone) {
 		return
 	}
 
@@ -42,5 +50,12 @@ func newFinalizer(callback finalizerCallback) *finalizer {
 }
 
 func (f *finalizer) stop() {
-	atomic.StoreInt32(&f.stopped, 1)
+	atomic.CompareAndSwapInt32(&f.state, finalizerRunning, finalizerStopped)
+}
+
+// resume restarts a stopped finalizer whose chain has not ended yet, and
+// reports whether it did. Otherwise, a new finalizer is needed.
+func (f *finalizer) resume() bool {
+	return atomic.CompareAndSwapInt32(&f.state, finalizerStopped, finalizerRunning) ||
+		atomic.LoadInt32(&f.state) == finalizerRunning
 }
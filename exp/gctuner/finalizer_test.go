@@ -41,3 +41,45 @@ func TestFinalizer(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalizerResume(t *testing.T) {
+	var count int32
+
+	prev := debug.SetGCPercent(-1)
+	defer debug.SetGCPercent(prev)
+
+	f := newFinalizer(func() {
+		atomic.AddInt32(&count, 1)
+	})
+
+	runtime.GC()
+	if atomic.LoadInt32(&count) == 0 {
+		t.Fatal("expected finalizer callback")
+	}
+
+	// A stopped finalizer resumes before its chain observes the stop.
+	f.stop()
+	if !f.resume() {
+		t.Fatal("expected finalizer to resume")
+	}
+
+	last := atomic.LoadInt32(&count)
+	runtime.GC()
+	if atomic.LoadInt32(&count) == last {
+		t.Fatal("expected resumed finalizer callback")
+	}
+
+	// Once the chain ended, it cannot resume.
+	f.stop()
+	runtime.GC()
+	runtime.GC()
+	if f.resume() {
+		t.Fatal("expected ended finalizer not to resume")
+	}
+
+	last = atomic.LoadInt32(&count)
+	runtime.GC()
+	if atomic.LoadInt32(&count) != last {
+		t.Fatal("expected no callback after the chain ended")
+	}
+}
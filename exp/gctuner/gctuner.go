@@ -0,0 +1,88 @@
+package gctuner
+
+// std is the default Tuner used by the package-level functions.
+var std = newTunerInstance()
+
+// Enable starts or updates GC tuning with the given threshold and options.
+//
+// Threshold semantics:
+//   - 0 disables the tuner, like [Disable]
+//   - -1 derives the threshold from the effective memory limit (or a
+//     [SetMemLimitPercent] override, if set)
+//   - >0 uses the provided byte value directly
+//
+// Enable returns an error if the threshold cannot be resolved (for -1), or if
+// any provided options are invalid.
+//
+// Enable configures and starts a default [Tuner]. It is the same as calling
+// [Tuner.Update] with [WithThreshold](threshold) and then [Tuner.Start].
+func Enable(threshold int64, opts ...Option) error {
+	if err := std.Update(append(opts, WithThreshold(threshold))...); err != nil {
+		return err
+	}
+
+	return std.Start()
+}
+
+// MustEnable is like Enable but panics on error.
+func MustEnable(threshold int64, opts ...Option) {
+	if err := Enable(threshold, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// GetGCPercent returns the current effective GC percent used by the tuner.
+// If the tuner is disabled, it returns the process default (GOGC or 100).
+func GetGCPercent() uint32 {
+	if !std.Running() {
+		return getDefaultGCPercent()
+	}
+
+	return std.GCPercent()
+}
+
+// GetMaxGCPercent returns the current maximum GC percent allowed.
+func GetMaxGCPercent() uint32 {
+	return std.MaxGCPercent()
+}
+
+// GetMinGCPercent returns the current minimum GC percent allowed.
+func GetMinGCPercent() uint32 {
+	return std.MinGCPercent()
+}
+
+// GetMemLimitPercent gets the memory limit based on the given percentage of the
+// detected memory limit and returns the value in bytes.
+//
+// If percent < 0, it returns the total memory limit in bytes. If percent == 0,
+// it returns 0. If percent > 100, it is clamped to 100. The detected memory
+// limit is the lowest of GOMEMLIMIT (Go 1.19+), [EnvMemoryLimit], the cgroup
+// limit, the physical memory, and the resource limits; see [DetectLimit].
+func GetMemLimitPercent(percent float64) uint64 {
+	return std.MemLimitPercent(percent)
+}
+
+// SetMemLimitPercent sets the Go memory limit based on a percentage of the
+// detected memory limit.
+//
+// On Go <= 1.19, it is a no-op. If percent resolves to 0, the override is
+// cleared. If percent > 100, it is clamped to 100.
+func SetMemLimitPercent(percent float64) {
+	std.SetMemLimitPercent(percent)
+}
+
+// Stats returns a snapshot of the state of the default tuner.
+//
+// If the tuner is disabled, only GCPercent (the process default) and the
+// bounds are set.
+func Stats() Snapshot {
+	if !std.Running() {
+		return Snapshot{
+			GCPercent:    getDefaultGCPercent(),
+			MinGCPercent: std.MinGCPercent(),
+			MaxGCPercent: std.MaxGCPercent(),
+		}
+	}
+
+	return std.Stats()
+}
@@ -0,0 +1,166 @@
+package gctuner
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+type fakeMemory struct {
+	inuse uint64
+	goal  uint64
+	limit uint64
+}
+
+func (m *fakeMemory) Heap() (uint64, uint64) {
+	return atomic.LoadUint64(&m.inuse), atomic.LoadUint64(&m.goal)
+}
+
+func (m *fakeMemory) MemoryLimit() uint64 {
+	return atomic.LoadUint64(&m.limit)
+}
+
+type fakeRuntime struct {
+	gcPercent   int64
+	memoryLimit int64
+}
+
+func (r *fakeRuntime) SetGCPercent(percent int) int {
+	return int(atomic.SwapInt64(&r.gcPercent, int64(percent)))
+}
+
+func (r *fakeRuntime) SetMemoryLimit(limit int64) int64 {
+	if limit < 0 {
+		return atomic.LoadInt64(&r.memoryLimit)
+	}
+
+	return atomic.SwapInt64(&r.memoryLimit, limit)
+}
+
+func TestTunerTune(t *testing.T) {
+	mem := &fakeMemory{inuse: 1 << 30, limit: 8 << 30}
+	rt := &fakeRuntime{gcPercent: 100}
+
+	tn, err := New(
+		WithMemorySource(mem),
+		WithRuntime(rt),
+		WithThreshold(4<<30),
+		WithMinGCPercent(20),
+		WithMaxGCPercent(1000),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	if tn.Running() {
+		t.Fatal("expected a new tuner not to be running")
+	}
+
+	d := tn.Tune()
+	if d.GCPercent != 300 || d.HeapInuse != 1<<30 || d.Threshold != 4<<30 {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+
+	if got := atomic.LoadInt64(&rt.gcPercent); got != 300 {
+		t.Fatalf("expected runtime gc percent 300, got %d", got)
+	}
+
+	atomic.StoreUint64(&mem.inuse, 3900<<20)
+	if d := tn.Tune(); d.GCPercent != 20 || !d.ClampedToMin || d.PrevGCPercent != 300 {
+		t.Fatalf("expected decision clamped to min 20, got %+v", d)
+	}
+
+	if s := tn.Stats(); s.Tunings != 2 || s.MinClamps != 1 || s.GCPercent != 20 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	// The default tuner is untouched.
+	if std.MinGCPercent() == 20 {
+		t.Fatal("expected the default tuner to keep its bounds")
+	}
+}
+
+func TestTunerDerivedThreshold(t *testing.T) {
+	mem := &fakeMemory{inuse: 1, limit: 1 << 30}
+
+	tn, err := New(WithMemorySource(mem), WithRuntime(&fakeRuntime{}))
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	if got := tn.Threshold(); got != 1<<30 {
+		t.Fatalf("expected threshold derived from the limit, got %d", got)
+	}
+
+	tn.SetMemLimitPercent(50)
+	if err := tn.Update(WithThreshold(-1)); err != nil {
+		t.Fatalf("unexpected error updating tuner: %v", err)
+	}
+
+	if got := tn.Threshold(); got != 1<<29 {
+		t.Fatalf("expected threshold from the override, got %d", got)
+	}
+
+	if _, err := New(WithMemorySource(&fakeMemory{})); err == nil {
+		t.Fatal("expected error for an unknown memory limit")
+	}
+}
+
+func TestTunerLifecycle(t *testing.T) {
+	rt := &fakeRuntime{}
+
+	tn, err := New(WithMemorySource(&fakeMemory{inuse: 1, limit: 1 << 20}), WithRuntime(rt))
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+
+	if err := tn.Start(); err != nil || !tn.Running() {
+		t.Fatalf("Start = %v, running = %v", err, tn.Running())
+	}
+
+	if err := tn.Start(); err != nil {
+		t.Fatalf("unexpected error starting a started tuner: %v", err)
+	}
+
+	tn.Stop()
+	if tn.Running() {
+		t.Fatal("expected tuner to be stopped")
+	}
+
+	if err := tn.Update(WithThreshold(0)); err != nil {
+		t.Fatalf("unexpected error updating tuner: %v", err)
+	}
+
+	if err := tn.Start(); err != nil || tn.Running() {
+		t.Fatalf("expected a zero threshold to keep the tuner stopped, got %v running=%v", err, tn.Running())
+	}
+
+	if d := tn.Tune(); d != (Decision{}) {
+		t.Fatalf("expected no decision with a zero threshold, got %+v", d)
+	}
+
+	if err := tn.Close(); err != nil {
+		t.Fatalf("unexpected error closing tuner: %v", err)
+	}
+
+	if err := tn.Start(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed from Start, got %v", err)
+	}
+
+	if err := tn.Update(); err != ErrClosed {
+		t.Fatalf("expected ErrClosed from Update, got %v", err)
+	}
+}
+
+func TestTunerOptionValidation(t *testing.T) {
+	for _, opt := range []Option{
+		WithMemorySource(nil),
+		WithRuntime(nil),
+		WithMinGCPercent(0),
+	} {
+		if _, err := New(WithThreshold(1024), opt); err == nil {
+			t.Fatal("expected error for invalid option")
+		}
+	}
+}
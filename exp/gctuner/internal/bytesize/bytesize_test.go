@@ -0,0 +1,54 @@
+package bytesize
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in     string
+		want   int64
+		wantOK bool
+	}{
+		{"1", 1, true},
+		{"1B", 1, true},
+		{"1KiB", 1024, true},
+		{"2MiB", 2 * 1024 * 1024, true},
+		{"1GiB", 1024 * 1024 * 1024, true},
+		{"1TiB", 1024 * 1024 * 1024 * 1024, true},
+		{"", 0, false},
+		{"K", 0, false},
+		{"1KB", 0, false},
+		{"1Ki", 0, false},
+		{"1XiB", 0, false},
+		{"-1", 0, false},
+		{"999999999999999999999999", 0, false},
+	}
+
+	for _, tc := range cases {
+		got, ok := Parse(tc.in)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("Parse(%q) = (%d,%v), want (%d,%v)", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestAtoi64(t *testing.T) {
+	cases := []struct {
+		in     string
+		want   int64
+		wantOK bool
+	}{
+		{"0", 0, true},
+		{"123", 123, true},
+		{"-1", -1, true},
+		{"", 0, false},
+		{"1a", 0, false},
+		{"999999999999999999999999", 0, false},
+	}
+
+	for _, tc := range cases {
+		got, ok := atoi64(tc.in)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("atoi64(%q) = (%d,%v), want (%d,%v)", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
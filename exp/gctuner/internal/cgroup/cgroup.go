@@ -0,0 +1,259 @@
+// nolint
+//go:build go1.16
+// +build go1.16
+
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when the memory cgroup of the process cannot be
+// found.
+var ErrNotFound = errors.New("memory cgroup not found")
+
+// Reader reads the memory cgroup of the current process from a root
+// filesystem.
+type Reader struct {
+	fsys fs.FS
+}
+
+// New returns a Reader for the root filesystem fsys, usually os.DirFS("/").
+//
+// Paths in fsys are relative to the root, so fsys can be a fixture tree
+// holding proc/self/mountinfo, proc/self/cgroup, and the cgroup files.
+func New(fsys fs.FS) *Reader {
+	return &Reader{fsys: fsys}
+}
+
+// Hierarchy is the memory cgroup of the process.
+type Hierarchy struct {
+	// Version is 1 or 2.
+	Version int
+	// MountPoint is where the hierarchy is mounted.
+	MountPoint string
+	// Dir is the cgroup directory of the process, under MountPoint.
+	Dir string
+}
+
+// Hierarchy finds the memory cgroup of the process.
+//
+// Mounts are read from /proc/self/mountinfo and the process cgroup from
+// /proc/self/cgroup. In hybrid setups, where both v1 and v2 hierarchies are
+// mounted, the hierarchy that holds the memory controller wins.
+func (r *Reader) Hierarchy() (Hierarchy, error) {
+	mountinfo, err := fs.ReadFile(r.fsys, "proc/self/mountinfo")
+	if err != nil {
+		return Hierarchy{}, err
+	}
+
+	self, err := fs.ReadFile(r.fsys, "proc/self/cgroup")
+	if err != nil {
+		return Hierarchy{}, err
+	}
+
+	mounts := parseMountInfo(string(mountinfo))
+	entries := parseProcCgroup(string(self))
+
+	// v1 memory controller first: in hybrid setups the v2 hierarchy has no
+	// controllers.
+	for _, e := range entries {
+		if e.id == "0" || !hasString(e.controllers, "memory") {
+			continue
+		}
+
+		for _, m := range mounts {
+			if m.fsType == "cgroup" && hasString(m.superOptions, "memory") {
+				return r.hierarchy(1, m, e.path), nil
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if e.id != "0" {
+			continue
+		}
+
+		for _, m := range mounts {
+			if m.fsType == "cgroup2" {
+				return r.hierarchy(2, m, e.path), nil
+			}
+		}
+	}
+
+	return Hierarchy{}, ErrNotFound
+}
+
+// hierarchy maps the cgroup path of the process onto mount m. If the mapped
+// directory is missing, as happens when a container sees the host cgroup
+// path without a cgroup namespace, the mount point is used instead.
+func (r *Reader) hierarchy(version int, m mount, cgroupPath string) Hierarchy {
+	h := Hierarchy{Version: version, MountPoint: m.mountPoint, Dir: m.mountPoint}
+
+	rel := cgroupPath
+	if m.root != "/" {
+		if cgroupPath != m.root && !strings.HasPrefix(cgroupPath, m.root+"/") {
+			return h
+		}
+		rel = strings.TrimPrefix(cgroupPath, m.root)
+	}
+
+	dir := path.Join(m.mountPoint, rel)
+	if fi, err := fs.Stat(r.fsys, fsPath(dir)); err == nil && fi.IsDir() {
+		h.Dir = dir
+	}
+
+	return h
+}
+
+// MemoryLimit returns the lowest memory limit of the process cgroup and its
+// ancestors up to the mount point, in bytes. On v2 both memory.max and
+// memory.high are considered. It returns 0 if no limit is set.
+func (r *Reader) MemoryLimit() (int64, error) {
+	h, err := r.Hierarchy()
+	if err != nil {
+		return 0, err
+	}
+
+	files := []string{"memory.limit_in_bytes"}
+	if h.Version == 2 {
+		files = []string{"memory.max", "memory.high"}
+	}
+
+	var (
+		limit int64
+		found bool
+	)
+
+	for dir := h.Dir; ; dir = path.Dir(dir) {
+		for _, name := range files {
+			n, unlimited, err := r.readValue(path.Join(dir, name))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return 0, err
+			}
+
+			found = true
+			if !unlimited && (limit == 0 || n < limit) {
+				limit = n
+			}
+		}
+
+		if dir == h.MountPoint || dir == "/" {
+			break
+		}
+	}
+
+	if !found {
+		return 0, fmt.Errorf("cannot find %s under %q", strings.Join(files, " or "), h.Dir)
+	}
+
+	return limit, nil
+}
+
+// MemoryUsage returns the memory usage of the process cgroup, in bytes.
+func (r *Reader) MemoryUsage() (int64, error) {
+	h, err := r.Hierarchy()
+	if err != nil {
+		return 0, err
+	}
+
+	name := "memory.usage_in_bytes"
+	if h.Version == 2 {
+		name = "memory.current"
+	}
+
+	n, _, err := r.readValue(path.Join(h.Dir, name))
+
+	return n, err
+}
+
+// MemoryPressure returns the contents of the memory.pressure file of the
+// process cgroup, in the PSI format.
+func (r *Reader) MemoryPressure() (string, error) {
+	h, err := r.Hierarchy()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := fs.ReadFile(r.fsys, fsPath(path.Join(h.Dir, "memory.pressure")))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// HierarchicalMemoryLimit returns hierarchical_memory_limit from the v1
+// memory.stat file of the process cgroup, in bytes.
+func (r *Reader) HierarchicalMemoryLimit() (int64, error) {
+	h, err := r.Hierarchy()
+	if err != nil {
+		return 0, err
+	}
+
+	if h.Version != 1 {
+		return 0, fmt.Errorf("hierarchical_memory_limit requires cgroup v1")
+	}
+
+	data, err := fs.ReadFile(r.fsys, fsPath(path.Join(h.Dir, "memory.stat")))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "hierarchical_memory_limit" {
+			return strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+
+	return 0, fmt.Errorf("cannot find hierarchical_memory_limit in %q", path.Join(h.Dir, "memory.stat"))
+}
+
+// readValue reads a single value from name. The bool result reports whether
+// the file holds "max", which means no limit.
+func (r *Reader) readValue(name string) (int64, bool, error) {
+	data, err := fs.ReadFile(r.fsys, fsPath(name))
+	if err != nil {
+		return 0, false, err
+	}
+
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, true, nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("cannot parse %q: %v", name, err)
+	}
+
+	return n, false, nil
+}
+
+// fsPath converts an absolute path to an fs.FS path.
+func fsPath(name string) string {
+	name = strings.TrimPrefix(path.Clean(name), "/")
+	if name == "" {
+		return "."
+	}
+
+	return name
+}
+
+func hasString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
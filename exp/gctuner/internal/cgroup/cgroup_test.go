@@ -0,0 +1,189 @@
+// nolint
+//go:build go1.16
+// +build go1.16
+
+package cgroup
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	mountinfoV2 = `24 1 0:22 / / rw,relatime - overlay overlay rw
+30 24 0:26 / /sys/fs/cgroup rw,nosuid,nodev,noexec,relatime - cgroup2 cgroup2 rw,nsdelegate
+`
+	mountinfoHybrid = `32 24 0:28 / /sys/fs/cgroup rw,relatime - tmpfs tmpfs rw,mode=755
+33 32 0:29 / /sys/fs/cgroup/cpu rw,relatime - cgroup cgroup rw,cpu
+36 32 0:32 / /sys/fs/cgroup/memory rw,relatime - cgroup cgroup rw,memory
+41 32 0:37 / /sys/fs/cgroup/systemd rw,relatime - cgroup cgroup rw,name=systemd
+42 32 0:38 / /sys/fs/cgroup/unified rw,relatime - cgroup2 cgroup2 rw
+`
+)
+
+func file(s string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(s)}
+}
+
+func TestMemoryLimitV2Ancestors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/self/mountinfo":                             file(mountinfoV2),
+		"proc/self/cgroup":                                file("0::/kubepods/pod1/ctr\n"),
+		"sys/fs/cgroup/memory.max":                        file("max\n"),
+		"sys/fs/cgroup/kubepods/memory.max":               file("max\n"),
+		"sys/fs/cgroup/kubepods/memory.high":              file("max\n"),
+		"sys/fs/cgroup/kubepods/pod1/memory.max":          file("536870912\n"),
+		"sys/fs/cgroup/kubepods/pod1/ctr/memory.max":      file("1073741824\n"),
+		"sys/fs/cgroup/kubepods/pod1/ctr/memory.high":     file("805306368\n"),
+		"sys/fs/cgroup/kubepods/pod1/ctr/memory.current":  file("4096\n"),
+		"sys/fs/cgroup/kubepods/pod1/ctr/cgroup.procs":    file("1\n"),
+		"sys/fs/cgroup/kubepods/pod1/ctr/memory.pressure": file("some avg10=1.00 avg60=0.00 avg300=0.00 total=1\n"),
+	}
+
+	r := New(fsys)
+
+	h, err := r.Hierarchy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Version != 2 || h.MountPoint != "/sys/fs/cgroup" || h.Dir != "/sys/fs/cgroup/kubepods/pod1/ctr" {
+		t.Fatalf("unexpected hierarchy: %+v", h)
+	}
+
+	// The pod limit is lower than the container's memory.max and memory.high.
+	if n, err := r.MemoryLimit(); err != nil || n != 536870912 {
+		t.Fatalf("MemoryLimit = (%d, %v), want (536870912, nil)", n, err)
+	}
+
+	if n, err := r.MemoryUsage(); err != nil || n != 4096 {
+		t.Fatalf("MemoryUsage = (%d, %v), want (4096, nil)", n, err)
+	}
+
+	if p, err := r.MemoryPressure(); err != nil || p == "" {
+		t.Fatalf("MemoryPressure = (%q, %v), want the file contents", p, err)
+	}
+
+	if _, err := r.HierarchicalMemoryLimit(); err == nil {
+		t.Fatal("expected HierarchicalMemoryLimit to fail on v2")
+	}
+}
+
+func TestMemoryLimitV2Unlimited(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/self/mountinfo":          file(mountinfoV2),
+		"proc/self/cgroup":             file("0::/\n"),
+		"sys/fs/cgroup/cgroup.procs":   file("1\n"),
+		"sys/fs/cgroup/memory.max":     file("max\n"),
+		"sys/fs/cgroup/memory.high":    file("max\n"),
+		"sys/fs/cgroup/memory.current": file("1\n"),
+	}
+
+	if n, err := New(fsys).MemoryLimit(); err != nil || n != 0 {
+		t.Fatalf("MemoryLimit = (%d, %v), want (0, nil)", n, err)
+	}
+}
+
+func TestMemoryLimitV1Hybrid(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/self/mountinfo": file(mountinfoHybrid),
+		"proc/self/cgroup": file(`9:name=systemd:/
+4:memory:/docker/abc
+1:cpu:/
+0::/
+`),
+		"sys/fs/cgroup/unified/memory.max":                      file("1024\n"),
+		"sys/fs/cgroup/memory/memory.limit_in_bytes":            file("9223372036854771712\n"),
+		"sys/fs/cgroup/memory/docker/memory.limit_in_bytes":     file("2147483648\n"),
+		"sys/fs/cgroup/memory/docker/abc/memory.limit_in_bytes": file("4294967296\n"),
+		"sys/fs/cgroup/memory/docker/abc/memory.usage_in_bytes": file("8192\n"),
+		"sys/fs/cgroup/memory/docker/abc/memory.stat":           file("cache 0\nhierarchical_memory_limit 2147483648\n"),
+	}
+
+	r := New(fsys)
+
+	h, err := r.Hierarchy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Version != 1 || h.Dir != "/sys/fs/cgroup/memory/docker/abc" {
+		t.Fatalf("unexpected hierarchy: %+v", h)
+	}
+
+	if n, err := r.MemoryLimit(); err != nil || n != 2147483648 {
+		t.Fatalf("MemoryLimit = (%d, %v), want (2147483648, nil)", n, err)
+	}
+
+	if n, err := r.MemoryUsage(); err != nil || n != 8192 {
+		t.Fatalf("MemoryUsage = (%d, %v), want (8192, nil)", n, err)
+	}
+
+	if n, err := r.HierarchicalMemoryLimit(); err != nil || n != 2147483648 {
+		t.Fatalf("HierarchicalMemoryLimit = (%d, %v), want (2147483648, nil)", n, err)
+	}
+}
+
+func TestHierarchyMountRoot(t *testing.T) {
+	// A container without a cgroup namespace: the hierarchy is mounted from
+	// the container's own cgroup, and /proc/self/cgroup shows the host path.
+	fsys := fstest.MapFS{
+		"proc/self/mountinfo": file(`30 24 0:26 /docker/abc /sys/fs/cgroup/memory ro,nosuid - cgroup cgroup rw,memory
+`),
+		"proc/self/cgroup":                           file("4:memory:/docker/abc/sub\n"),
+		"sys/fs/cgroup/memory/memory.limit_in_bytes": file("1048576\n"),
+		"sys/fs/cgroup/memory/sub/cgroup.procs":      file("1\n"),
+	}
+
+	h, err := New(fsys).Hierarchy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Dir != "/sys/fs/cgroup/memory/sub" {
+		t.Fatalf("expected dir under the mount root, got %+v", h)
+	}
+
+	if n, err := New(fsys).MemoryLimit(); err != nil || n != 1048576 {
+		t.Fatalf("MemoryLimit = (%d, %v), want (1048576, nil)", n, err)
+	}
+}
+
+func TestHierarchyMissingDir(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/self/mountinfo":        file(mountinfoV2),
+		"proc/self/cgroup":           file("0::/host/path\n"),
+		"sys/fs/cgroup/memory.max":   file("65536\n"),
+		"sys/fs/cgroup/cgroup.procs": file("1\n"),
+	}
+
+	h, err := New(fsys).Hierarchy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Dir != "/sys/fs/cgroup" {
+		t.Fatalf("expected fallback to the mount point, got %+v", h)
+	}
+}
+
+func TestHierarchyNotFound(t *testing.T) {
+	fsys := fstest.MapFS{
+		"proc/self/mountinfo": file("24 1 0:22 / / rw - overlay overlay rw\n"),
+		"proc/self/cgroup":    file("0::/\n"),
+	}
+
+	if _, err := New(fsys).Hierarchy(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseMountInfoEscapes(t *testing.T) {
+	mounts := parseMountInfo(`30 24 0:26 / /sys/fs/my\040cgroup rw shared:1 master:2 - cgroup2 cgroup2 rw
+31 24 0:27 / /proc rw - proc proc rw
+`)
+	if len(mounts) != 1 {
+		t.Fatalf("expected one cgroup mount, got %+v", mounts)
+	}
+
+	if m := mounts[0]; m.mountPoint != "/sys/fs/my cgroup" || m.fsType != "cgroup2" {
+		t.Fatalf("unexpected mount: %+v", m)
+	}
+}
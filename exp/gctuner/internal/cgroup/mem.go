@@ -1,38 +1,44 @@
+// nolint
+//go:build go1.16
+// +build go1.16
+
 package cgroup
 
-import (
-	"strconv"
-)
+import "os"
 
 // GetMemoryLimit returns cgroup memory limit
+//
+// It is the lowest memory.max or memory.high (v2), or memory.limit_in_bytes
+// (v1), of the current cgroup and its ancestors. It returns 0 if no limit is
+// set or the limit cannot be read.
 func GetMemoryLimit() int64 {
-	// Try determining the amount of memory inside docker container.
-	// See https://stackoverflow.com/questions/42187085/check-mem-limit-within-a-docker-container
-	//
-	// Read memory limit according to https://unix.stackexchange.com/questions/242718/how-to-find-out-how-much-memory-lxc-container-is-allowed-to-consume
-	// This should properly determine the limit inside lxc container.
-	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/84
-	n, err := getMemStat("memory.limit_in_bytes")
-	if err == nil {
-		return n
+	n, _ := GetMemoryLimitVersion()
+
+	return n
+}
+
+// GetMemoryLimitVersion returns the cgroup memory limit, like
+// GetMemoryLimit, and the cgroup version (1 or 2) it was read from. It
+// returns 0, 0 if the limit cannot be read.
+func GetMemoryLimitVersion() (int64, int) {
+	r := New(os.DirFS("/"))
+
+	h, err := r.Hierarchy()
+	if err != nil {
+		return 0, 0
 	}
 
-	n, err = getMemStatV2("memory.max")
+	n, err := r.MemoryLimit()
 	if err != nil {
-		return 0
+		return 0, 0
 	}
 
-	return n
+	return n, h.Version
 }
 
 // GetMemoryUsage returns cgroup memory usage
 func GetMemoryUsage() int64 {
-	n, err := getMemStat("memory.usage_in_bytes")
-	if err == nil {
-		return n
-	}
-
-	n, err = getMemStatV2("memory.current")
+	n, err := New(os.DirFS("/")).MemoryUsage()
 	if err != nil {
 		return 0
 	}
@@ -40,21 +46,11 @@ func GetMemoryUsage() int64 {
 	return n
 }
 
-// see https://www.kernel.org/doc/Documentation/cgroup-v2.txt
-func getMemStatV2(statName string) (int64, error) {
-	// See https: //www.kernel.org/doc/html/latest/admin-guide/cgroup-v2.html#memory-interface-files
-	return getStatGeneric(statName, "/sys/fs/cgroup", "/proc/self/cgroup", "")
-}
-
-func getMemStat(statName string) (int64, error) {
-	return getStatGeneric(statName, "/sys/fs/cgroup/memory", "/proc/self/cgroup", "memory")
-}
-
 // GetHierarchicalMemoryLimit returns hierarchical memory limit
 // https://www.kernel.org/doc/Documentation/cgroup-v1/memory.txt
 func GetHierarchicalMemoryLimit() int64 {
 	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/699
-	n, err := getHierarchicalMemoryLimit("/sys/fs/cgroup/memory", "/proc/self/cgroup")
+	n, err := New(os.DirFS("/")).HierarchicalMemoryLimit()
 	if err != nil {
 		return 0
 	}
@@ -62,16 +58,8 @@ func GetHierarchicalMemoryLimit() int64 {
 	return n
 }
 
-func getHierarchicalMemoryLimit(sysfsPrefix, cgroupPath string) (int64, error) {
-	data, err := getFileContents("memory.stat", sysfsPrefix, cgroupPath, "memory")
-	if err != nil {
-		return 0, err
-	}
-
-	memStat, err := grepFirstMatch(data, "hierarchical_memory_limit", 1, " ")
-	if err != nil {
-		return 0, err
-	}
-
-	return strconv.ParseInt(memStat, 10, 64)
+// GetMemoryPressure returns the contents of the memory.pressure file of the
+// process cgroup, in the PSI format.
+func GetMemoryPressure() (string, error) {
+	return New(os.DirFS("/")).MemoryPressure()
 }
@@ -0,0 +1,96 @@
+// nolint
+//go:build !go1.16
+// +build !go1.16
+
+package cgroup
+
+import (
+	"strconv"
+)
+
+// GetMemoryLimit returns cgroup memory limit
+func GetMemoryLimit() int64 {
+	n, _ := GetMemoryLimitVersion()
+
+	return n
+}
+
+// GetMemoryLimitVersion returns the cgroup memory limit, like
+// GetMemoryLimit, and the cgroup version (1 or 2) it was read from. It
+// returns 0, 0 if the limit cannot be read.
+func GetMemoryLimitVersion() (int64, int) {
+	// Try determining the amount of memory inside docker container.
+	// See https://stackoverflow.com/questions/42187085/check-mem-limit-within-a-docker-container
+	//
+	// Read memory limit according to https://unix.stackexchange.com/questions/242718/how-to-find-out-how-much-memory-lxc-container-is-allowed-to-consume
+	// This should properly determine the limit inside lxc container.
+	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/84
+	n, err := getMemStat("memory.limit_in_bytes")
+	if err == nil {
+		return n, 1
+	}
+
+	n, err = getMemStatV2("memory.max")
+	if err != nil {
+		return 0, 0
+	}
+
+	return n, 2
+}
+
+// GetMemoryUsage returns cgroup memory usage
+func GetMemoryUsage() int64 {
+	n, err := getMemStat("memory.usage_in_bytes")
+	if err == nil {
+		return n
+	}
+
+	n, err = getMemStatV2("memory.current")
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// see https://www.kernel.org/doc/Documentation/cgroup-v2.txt
+func getMemStatV2(statName string) (int64, error) {
+	// See https: //www.kernel.org/doc/html/latest/admin-guide/cgroup-v2.html#memory-interface-files
+	return getStatGeneric(statName, "/sys/fs/cgroup", "/proc/self/cgroup", "")
+}
+
+func getMemStat(statName string) (int64, error) {
+	return getStatGeneric(statName, "/sys/fs/cgroup/memory", "/proc/self/cgroup", "memory")
+}
+
+// GetHierarchicalMemoryLimit returns hierarchical memory limit
+// https://www.kernel.org/doc/Documentation/cgroup-v1/memory.txt
+func GetHierarchicalMemoryLimit() int64 {
+	// See https://github.com/VictoriaMetrics/VictoriaMetrics/issues/699
+	n, err := getHierarchicalMemoryLimit("/sys/fs/cgroup/memory", "/proc/self/cgroup")
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+func getHierarchicalMemoryLimit(sysfsPrefix, cgroupPath string) (int64, error) {
+	data, err := getFileContents("memory.stat", sysfsPrefix, cgroupPath, "memory")
+	if err != nil {
+		return 0, err
+	}
+
+	memStat, err := grepFirstMatch(data, "hierarchical_memory_limit", 1, " ")
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(memStat, 10, 64)
+}
+
+// GetMemoryPressure returns the contents of the memory.pressure file of the
+// process cgroup, in the PSI format.
+func GetMemoryPressure() (string, error) {
+	return getFileContents("memory.pressure", "/sys/fs/cgroup", "/proc/self/cgroup", "")
+}
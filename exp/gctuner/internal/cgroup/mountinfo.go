@@ -0,0 +1,114 @@
+// nolint
+//go:build go1.16
+// +build go1.16
+
+package cgroup
+
+import (
+	"strings"
+)
+
+type mount struct {
+	root         string
+	mountPoint   string
+	fsType       string
+	superOptions []string
+}
+
+// parseMountInfo parses /proc/self/mountinfo, keeping cgroup and cgroup2
+// mounts. See proc(5).
+//
+//	36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfo(data string) []mount {
+	var mounts []mount
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+
+		sep := -1
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				sep = i
+				break
+			}
+		}
+
+		if sep < 0 || sep+1 >= len(fields) {
+			continue
+		}
+
+		fsType := fields[sep+1]
+		if fsType != "cgroup" && fsType != "cgroup2" {
+			continue
+		}
+
+		m := mount{
+			root:       unescapeMountPath(fields[3]),
+			mountPoint: unescapeMountPath(fields[4]),
+			fsType:     fsType,
+		}
+
+		if len(fields) > sep+3 {
+			m.superOptions = strings.Split(fields[sep+3], ",")
+		}
+
+		mounts = append(mounts, m)
+	}
+
+	return mounts
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for space, and so on)
+// the kernel uses in mountinfo paths.
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			b.WriteByte((s[i+1]-'0')<<6 | (s[i+2]-'0')<<3 | (s[i+3] - '0'))
+			i += 3
+
+			continue
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
+
+func isOctal(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+type procCgroup struct {
+	id          string
+	controllers []string
+	path        string
+}
+
+// parseProcCgroup parses /proc/self/cgroup lines of the form
+// "hierarchy-ID:controller-list:cgroup-path". The v2 entry has ID 0 and an
+// empty controller list.
+func parseProcCgroup(data string) []procCgroup {
+	var entries []procCgroup
+
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		e := procCgroup{id: parts[0], path: parts[2]}
+		if parts[1] != "" {
+			e.controllers = strings.Split(parts[1], ",")
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
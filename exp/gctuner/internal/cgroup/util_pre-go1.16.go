@@ -1,3 +1,7 @@
+// nolint
+//go:build !go1.16
+// +build !go1.16
+
 package cgroup
 
 import (
@@ -1,11 +1,33 @@
 package memory
 
+// Sources of the limits returned by GetMemoryLimits.
+const (
+	SourcePhysical   = "physical memory"
+	SourceCgroupV1   = "cgroup v1"
+	SourceCgroupV2   = "cgroup v2"
+	SourceRlimitAS   = "RLIMIT_AS"
+	SourceRlimitData = "RLIMIT_DATA"
+)
+
+// Limit is a memory limit and the source it was read from.
+type Limit struct {
+	Source string
+	Bytes  uint64
+}
+
 // GetMemoryLimit returns system memory limit
 // if cgroup is used, it returns cgroup memory limit
 func GetMemoryLimit() uint64 {
 	return sysTotalMemory()
 }
 
+// GetMemoryLimits returns every memory limit known to the system: the
+// physical memory, the cgroup limit on Linux, and the RLIMIT_AS and
+// RLIMIT_DATA resource limits on Unix. Unset or unknown limits are omitted.
+func GetMemoryLimits() []Limit {
+	return append(sysMemoryLimits(), sysResourceLimits()...)
+}
+
 // GetMemoryFree returns memory free
 func GetMemoryFree() uint64 {
 	return sysFreeMemory()
@@ -18,16 +18,44 @@ func sysTotalMemory() uint64 {
 		return 0
 	}
 
-	mem := cgroup.GetMemoryLimit()
+	if mem, _ := cgroupMemoryLimit(totalMem); mem > 0 {
+		return mem
+	}
+
+	return totalMem
+}
+
+func sysMemoryLimits() []Limit {
+	totalMem := readMemInfoValue("MemTotal")
+	if totalMem == 0 {
+		return nil
+	}
+
+	limits := []Limit{{Source: SourcePhysical, Bytes: totalMem}}
+	if mem, version := cgroupMemoryLimit(totalMem); mem > 0 {
+		source := SourceCgroupV2
+		if version == 1 {
+			source = SourceCgroupV1
+		}
+		limits = append(limits, Limit{Source: source, Bytes: mem})
+	}
+
+	return limits
+}
+
+// cgroupMemoryLimit returns the cgroup memory limit and its cgroup version,
+// or 0, 0 if it is unset or larger than totalMem.
+func cgroupMemoryLimit(totalMem uint64) (uint64, int) {
+	mem, version := cgroup.GetMemoryLimitVersion()
 	if mem <= 0 || int64(int(mem)) != mem || uint64(mem) > totalMem {
 		// Try reading hierarchical memory limit.
-		mem = cgroup.GetHierarchicalMemoryLimit()
+		mem, version = cgroup.GetHierarchicalMemoryLimit(), 1
 		if mem <= 0 || int64(int(mem)) != mem || uint64(mem) > totalMem {
-			return totalMem
+			return 0, 0
 		}
 	}
 
-	return uint64(mem)
+	return uint64(mem), version
 }
 
 func sysFreeMemory() uint64 {
@@ -0,0 +1,14 @@
+// nolint
+//go:build !linux
+// +build !linux
+
+package memory
+
+func sysMemoryLimits() []Limit {
+	totalMem := sysTotalMemory()
+	if totalMem == 0 {
+		return nil
+	}
+
+	return []Limit{{Source: SourcePhysical, Bytes: totalMem}}
+}
@@ -0,0 +1,44 @@
+// nolint
+//go:build linux || darwin || freebsd || netbsd || dragonfly
+// +build linux darwin freebsd netbsd dragonfly
+
+package memory
+
+import (
+	"math"
+	"syscall"
+)
+
+func sysResourceLimits() []Limit {
+	var limits []Limit
+	for _, r := range []struct {
+		source   string
+		resource int
+	}{
+		{SourceRlimitAS, syscall.RLIMIT_AS},
+		{SourceRlimitData, syscall.RLIMIT_DATA},
+	} {
+		if n := getrlimit(r.resource); n > 0 {
+			limits = append(limits, Limit{Source: r.source, Bytes: n})
+		}
+	}
+
+	return limits
+}
+
+// getrlimit returns the soft limit of resource, or 0 if it is unlimited or
+// cannot be read.
+func getrlimit(resource int) uint64 {
+	var rl syscall.Rlimit
+	if err := syscall.Getrlimit(resource, &rl); err != nil {
+		return 0
+	}
+
+	// RLIM_INFINITY is math.MaxUint64 on Linux and math.MaxInt64 on the BSDs.
+	cur := uint64(rl.Cur)
+	if cur >= math.MaxInt64 {
+		return 0
+	}
+
+	return cur
+}
@@ -0,0 +1,9 @@
+// nolint
+//go:build !linux && !darwin && !freebsd && !netbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!dragonfly
+
+package memory
+
+func sysResourceLimits() []Limit {
+	return nil
+}
@@ -0,0 +1,157 @@
+package gctuner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.dw1.io/x/exp/gctuner/internal/bytesize"
+	"go.dw1.io/x/exp/gctuner/internal/memory"
+)
+
+// EnvMemoryLimit is the environment variable read as a memory limit source.
+//
+// It holds either a byte count, or the absolute path of a file containing
+// one, so it can be set from the Kubernetes downward API with a
+// resourceFieldRef to limits.memory, as an environment variable or as a
+// volume file. Byte counts use the GOMEMLIMIT format.
+const EnvMemoryLimit = "MEMORY_LIMIT"
+
+// LimitSource names where a memory limit was read from.
+type LimitSource string
+
+// Memory limit sources reported by [DetectLimit].
+const (
+	LimitSourceGOMEMLIMIT   LimitSource = "GOMEMLIMIT"
+	LimitSourceCgroupV1     LimitSource = memory.SourceCgroupV1
+	LimitSourceCgroupV2     LimitSource = memory.SourceCgroupV2
+	LimitSourcePhysical     LimitSource = memory.SourcePhysical
+	LimitSourceRlimitAS     LimitSource = memory.SourceRlimitAS
+	LimitSourceRlimitData   LimitSource = memory.SourceRlimitData
+	LimitSourceEnv          LimitSource = EnvMemoryLimit
+	LimitSourceMemorySource LimitSource = "MemorySource" // set with WithMemorySource
+	LimitSourceCustom       LimitSource = "WithLimitSource"
+)
+
+// Limit is a memory limit reported by one source.
+type Limit struct {
+	Source LimitSource
+	Bytes  uint64
+}
+
+// DetectedLimit is the effective memory limit and how it was found, see
+// [DetectLimit].
+type DetectedLimit struct {
+	// Limit is the lowest of Sources, in bytes, or 0 if none is known.
+	Limit uint64
+	// Source is the source Limit was read from.
+	Source LimitSource
+	// Sources lists every source that reported a limit.
+	Sources []Limit
+}
+
+// DetectLimit returns the effective memory limit of the default tuner. See
+// [Tuner.DetectLimit].
+func DetectLimit() DetectedLimit {
+	return std.DetectLimit()
+}
+
+// DetectLimit returns the effective memory limit, which thresholds and
+// [SetMemLimitPercent] are derived from, and the source it was read from.
+//
+// It is the lowest of the limits reported by the [MemorySource] and the
+// [WithLimitSource] function. For the current process, the default, these
+// are GOMEMLIMIT (Go 1.19+), [EnvMemoryLimit], the cgroup limit, the
+// physical memory, and the RLIMIT_AS and RLIMIT_DATA resource limits.
+func (t *Tuner) DetectLimit() DetectedLimit {
+	var sources []Limit
+	if src, ok := t.getSource().(limitLister); ok {
+		sources = src.MemoryLimits()
+	} else if n := t.getSource().MemoryLimit(); n > 0 {
+		sources = []Limit{{Source: LimitSourceMemorySource, Bytes: n}}
+	}
+
+	if fn := t.getLimitSource(); fn != nil {
+		if n := fn(); n > 0 {
+			sources = append(sources, Limit{Source: LimitSourceCustom, Bytes: n})
+		}
+	}
+
+	return lowestLimit(sources)
+}
+
+// memoryLimit returns the effective memory limit, see [Tuner.DetectLimit].
+func (t *Tuner) memoryLimit() uint64 {
+	return t.DetectLimit().Limit
+}
+
+type limitSourceFunc func() uint64
+
+func (t *Tuner) getLimitSource() func() uint64 {
+	fn, _ := t.limitSource.Load().(limitSourceFunc)
+
+	return fn
+}
+
+// limitLister is implemented by a [MemorySource] that knows the individual
+// limits its MemoryLimit is the lowest of.
+type limitLister interface {
+	MemoryLimits() []Limit
+}
+
+// lowestLimit returns the lowest of sources. On a tie, the first one wins.
+func lowestLimit(sources []Limit) DetectedLimit {
+	d := DetectedLimit{Sources: sources}
+	for _, l := range sources {
+		if l.Bytes > 0 && (d.Limit == 0 || l.Bytes < d.Limit) {
+			d.Limit = l.Bytes
+			d.Source = l.Source
+		}
+	}
+
+	return d
+}
+
+// processLimits returns the memory limits of the current process, in order
+// of precedence on a tie.
+func processLimits() []Limit {
+	var limits []Limit
+	if n := readGOMEMLIMIT(); n > 0 {
+		limits = append(limits, Limit{Source: LimitSourceGOMEMLIMIT, Bytes: uint64(n)})
+	}
+
+	if n := readMemoryLimitEnv(); n > 0 {
+		limits = append(limits, Limit{Source: LimitSourceEnv, Bytes: n})
+	}
+
+	for _, l := range memory.GetMemoryLimits() {
+		limits = append(limits, Limit{Source: LimitSource(l.Source), Bytes: l.Bytes})
+	}
+
+	return limits
+}
+
+// readMemoryLimitEnv reads the [EnvMemoryLimit] value, following it to a
+// file if it is an absolute path. Returns 0 if unset or invalid.
+func readMemoryLimitEnv() uint64 {
+	v := strings.TrimSpace(os.Getenv(EnvMemoryLimit))
+	if v == "" {
+		return 0
+	}
+
+	if filepath.IsAbs(v) {
+		data, err := ioutil.ReadFile(v)
+		if err != nil {
+			return 0
+		}
+		v = strings.TrimSpace(string(data))
+	}
+
+	n, ok := bytesize.Parse(v)
+	if !ok || n <= 0 {
+		return 0
+	}
+
+	return uint64(n)
+}
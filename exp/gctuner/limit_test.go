@@ -0,0 +1,146 @@
+package gctuner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+// setMemoryLimitEnv sets EnvMemoryLimit and returns a func that restores it.
+func setMemoryLimitEnv(value string) func() {
+	old, ok := os.LookupEnv(EnvMemoryLimit)
+	_ = os.Setenv(EnvMemoryLimit, value)
+
+	return func() {
+		if ok {
+			_ = os.Setenv(EnvMemoryLimit, old)
+		} else {
+			_ = os.Unsetenv(EnvMemoryLimit)
+		}
+	}
+}
+
+func TestLowestLimit(t *testing.T) {
+	sources := []Limit{
+		{Source: LimitSourcePhysical, Bytes: 8 << 30},
+		{Source: LimitSourceCgroupV2, Bytes: 2 << 30},
+		{Source: LimitSourceRlimitAS, Bytes: 2 << 30},
+		{Source: LimitSourceCustom, Bytes: 0},
+	}
+
+	d := lowestLimit(sources)
+	if d.Limit != 2<<30 || d.Source != LimitSourceCgroupV2 {
+		t.Fatalf("expected the first lowest limit, got %+v", d)
+	}
+
+	if !reflect.DeepEqual(d.Sources, sources) {
+		t.Fatalf("expected all sources, got %v", d.Sources)
+	}
+
+	if d := lowestLimit(nil); d.Limit != 0 || d.Source != "" {
+		t.Fatalf("expected no limit, got %+v", d)
+	}
+}
+
+func TestReadMemoryLimitEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gctuner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "limits.memory")
+	if err := ioutil.WriteFile(file, []byte("536870912\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	restore := setMemoryLimitEnv("")
+	defer restore()
+
+	for value, want := range map[string]uint64{
+		"":                               0,
+		"1073741824":                     1 << 30,
+		" 512MiB ":                       512 << 20,
+		"0":                              0,
+		"-1":                             0,
+		"not-a-number":                   0,
+		file:                             512 << 20,
+		filepath.Join(dir, "missing"):    0,
+		filepath.Join(dir, "..", "none"): 0,
+	} {
+		_ = os.Setenv(EnvMemoryLimit, value)
+		if got := readMemoryLimitEnv(); got != want {
+			t.Fatalf("%q: expected %d, got %d", value, want, got)
+		}
+	}
+}
+
+func TestDetectLimitProcess(t *testing.T) {
+	restore := setMemoryLimitEnv("1MiB")
+	defer restore()
+
+	tn, err := New(WithThreshold(1 << 30))
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	d := tn.DetectLimit()
+	if d.Limit != 1<<20 || d.Source != LimitSourceEnv {
+		t.Fatalf("expected %s to win, got %+v", EnvMemoryLimit, d)
+	}
+
+	if got := (processMemory{}).MemoryLimit(); got != d.Limit {
+		t.Fatalf("expected process memory limit %d, got %d", d.Limit, got)
+	}
+}
+
+func TestWithLimitSource(t *testing.T) {
+	mem := &fakeMemory{limit: 4 << 30}
+
+	var custom uint64 = 1 << 30
+	tn, err := New(
+		WithMemorySource(mem),
+		WithRuntime(&fakeRuntime{gcPercent: 100}),
+		WithLimitSource(func() uint64 { return atomic.LoadUint64(&custom) }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	want := DetectedLimit{
+		Limit:  1 << 30,
+		Source: LimitSourceCustom,
+		Sources: []Limit{
+			{Source: LimitSourceMemorySource, Bytes: 4 << 30},
+			{Source: LimitSourceCustom, Bytes: 1 << 30},
+		},
+	}
+	if d := tn.DetectLimit(); !reflect.DeepEqual(d, want) {
+		t.Fatalf("expected %+v, got %+v", want, d)
+	}
+
+	// The threshold is derived from the lowest limit.
+	if got := tn.Threshold(); got != 1<<30 {
+		t.Fatalf("expected threshold derived from the custom limit, got %d", got)
+	}
+
+	// An unknown custom limit is skipped.
+	atomic.StoreUint64(&custom, 0)
+	if d := tn.DetectLimit(); d.Limit != 4<<30 || d.Source != LimitSourceMemorySource || len(d.Sources) != 1 {
+		t.Fatalf("expected the memory source to win, got %+v", d)
+	}
+
+	atomic.StoreUint64(&custom, 1<<30)
+	if err := tn.Update(WithLimitSource(nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := tn.DetectLimit(); d.Source != LimitSourceMemorySource {
+		t.Fatalf("expected the limit source to be removed, got %+v", d)
+	}
+}
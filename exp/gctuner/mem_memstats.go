@@ -0,0 +1,37 @@
+// nolint
+//go:build !go1.19
+// +build !go1.19
+
+package gctuner
+
+import (
+	"runtime"
+	"sync"
+)
+
+var (
+	memStatsMu sync.Mutex
+	memStats   runtime.MemStats
+)
+
+// readHeap returns the heap in use and the heap goal, in bytes.
+func readHeap() (inuse, goal uint64) {
+	memStatsMu.Lock()
+	defer memStatsMu.Unlock()
+
+	runtime.ReadMemStats(&memStats)
+
+	return memStats.HeapInuse, memStats.NextGC
+}
+
+// readNonHeap returns the memory held by the runtime outside of the heap,
+// such as goroutine stacks, GC metadata, and profiling buckets, in bytes.
+func readNonHeap() uint64 {
+	memStatsMu.Lock()
+	defer memStatsMu.Unlock()
+
+	runtime.ReadMemStats(&memStats)
+
+	return memStats.StackSys + memStats.MSpanSys + memStats.MCacheSys +
+		memStats.BuckHashSys + memStats.GCSys + memStats.OtherSys
+}
@@ -0,0 +1,94 @@
+// nolint
+//go:build go1.19
+// +build go1.19
+
+package gctuner
+
+import (
+	"runtime/metrics"
+	"sync"
+)
+
+const (
+	metricHeapObjects = "/memory/classes/heap/objects:bytes"
+	metricHeapLive    = "/gc/heap/live:bytes" // Go 1.21+
+	metricHeapGoal    = "/gc/heap/goal:bytes"
+)
+
+// nonHeapMetrics are the runtime memory classes outside of the heap.
+var nonHeapMetrics = []string{
+	"/memory/classes/heap/stacks:bytes",
+	"/memory/classes/os-stacks:bytes",
+	"/memory/classes/metadata/mcache/free:bytes",
+	"/memory/classes/metadata/mcache/inuse:bytes",
+	"/memory/classes/metadata/mspan/free:bytes",
+	"/memory/classes/metadata/mspan/inuse:bytes",
+	"/memory/classes/metadata/other:bytes",
+	"/memory/classes/profiling/buckets:bytes",
+	"/memory/classes/other:bytes",
+}
+
+var (
+	heapSamplesMu sync.Mutex
+	heapSamples   = []metrics.Sample{
+		{Name: metricHeapObjects},
+		{Name: metricHeapLive},
+		{Name: metricHeapGoal},
+	}
+
+	nonHeapSamplesMu sync.Mutex
+	nonHeapSamples   = newSamples(nonHeapMetrics)
+)
+
+func newSamples(names []string) []metrics.Sample {
+	samples := make([]metrics.Sample, len(names))
+	for i, name := range names {
+		samples[i].Name = name
+	}
+
+	return samples
+}
+
+// readHeap returns the heap in use and the heap goal, in bytes.
+//
+// It reads runtime/metrics, which unlike runtime.ReadMemStats does not stop
+// the world. The heap in use is the live heap marked by the last GC cycle, or
+// the heap occupied by objects on Go < 1.21, where the former is not
+// available.
+func readHeap() (inuse, goal uint64) {
+	heapSamplesMu.Lock()
+	defer heapSamplesMu.Unlock()
+
+	metrics.Read(heapSamples)
+
+	inuse = sampleUint64(heapSamples[1])
+	if inuse == 0 {
+		inuse = sampleUint64(heapSamples[0])
+	}
+
+	return inuse, sampleUint64(heapSamples[2])
+}
+
+// readNonHeap returns the memory held by the runtime outside of the heap,
+// such as goroutine stacks, GC metadata, and profiling buckets, in bytes.
+func readNonHeap() uint64 {
+	nonHeapSamplesMu.Lock()
+	defer nonHeapSamplesMu.Unlock()
+
+	metrics.Read(nonHeapSamples)
+
+	var total uint64
+	for _, s := range nonHeapSamples {
+		total += sampleUint64(s)
+	}
+
+	return total
+}
+
+func sampleUint64(s metrics.Sample) uint64 {
+	if s.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+
+	return s.Value.Uint64()
+}
@@ -2,6 +2,7 @@ package gctuner
 
 import (
 	"runtime"
+	"sync"
 	"testing"
 )
 
@@ -11,12 +12,66 @@ func TestMem(t *testing.T) {
 	const mb = 1024 * 1024
 
 	heap := make([]byte, 100*mb+1)
-	inuse := readMemoryInuse()
-	t.Logf("mem inuse: %d MB", inuse/mb)
+	runtime.GC() // the live heap is measured by the last GC cycle
+	inuse, goal := readHeap()
+	t.Logf("mem inuse: %d MB, goal: %d MB", inuse/mb, goal/mb)
 
 	if inuse < uint64(100*mb) {
 		t.Fatalf("expected inuse >= %d, got %d", 100*mb, inuse)
 	}
 
+	if goal < inuse {
+		t.Fatalf("expected goal >= inuse %d, got %d", inuse, goal)
+	}
+
 	heap[0] = 0
 }
+
+// benchHeap sets up a large heap with many goroutines, where stopping the
+// world is expensive.
+func benchHeap(b *testing.B) func() {
+	b.Helper()
+
+	heap := make([][]byte, 4096)
+	for i := range heap {
+		heap[i] = make([]byte, 64*1024)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 10000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-done
+		}()
+	}
+
+	runtime.GC()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	return func() {
+		b.StopTimer()
+		close(done)
+		wg.Wait()
+		runtime.KeepAlive(heap)
+	}
+}
+
+func BenchmarkReadHeap(b *testing.B) {
+	defer benchHeap(b)()
+
+	for i := 0; i < b.N; i++ {
+		readHeap()
+	}
+}
+
+func BenchmarkReadMemStats(b *testing.B) {
+	defer benchHeap(b)()
+
+	var ms runtime.MemStats
+	for i := 0; i < b.N; i++ {
+		runtime.ReadMemStats(&ms)
+	}
+}
@@ -0,0 +1,9 @@
+// Package metrics exposes the gctuner state for monitoring.
+//
+// It reads [gctuner.Stats] on demand and publishes it through [expvar] with
+// [Publish] or [Var], and in the Prometheus text exposition format with
+// [Handler]. It has no dependency on the Prometheus client library.
+//
+// Counters are kept by the running tuner, so they restart from zero when the
+// tuner is disabled and enabled again.
+package metrics
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"bytes"
+	"expvar"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+// ExpvarName is the name used by [Publish].
+const ExpvarName = "gctuner"
+
+// contentType is the Prometheus text exposition format content type.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var publishOnce sync.Once
+
+// Publish registers the tuner state with expvar under [ExpvarName]. It is
+// safe to call more than once.
+func Publish() {
+	publishOnce.Do(func() {
+		expvar.Publish(ExpvarName, Var())
+	})
+}
+
+// Var returns an expvar.Var that reports the tuner state as a JSON object.
+// Use it to publish the state under a custom name.
+func Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		s := gctuner.Stats()
+
+		return map[string]interface{}{
+			"enabled":             s.Enabled,
+			"gc_percent":          s.GCPercent,
+			"min_gc_percent":      s.MinGCPercent,
+			"max_gc_percent":      s.MaxGCPercent,
+			"threshold_bytes":     s.Threshold,
+			"memory_limit_bytes":  s.MemoryLimit,
+			"heap_inuse_bytes":    s.HeapInuse,
+			"heap_goal_bytes":     s.HeapGoal,
+			"nonheap_bytes":       s.NonHeap,
+			"tunings":             s.Tunings,
+			"min_clamps":          s.MinClamps,
+			"max_clamps":          s.MaxClamps,
+			"pressure_level":      s.PressureLevel.String(),
+			"emergencies":         s.Emergencies,
+			"last_tuning_unix_ns": lastTuningUnixNano(s),
+		}
+	})
+}
+
+// Handler returns an http.Handler that serves the tuner state in the
+// Prometheus text exposition format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(formatText(gctuner.Stats()))
+	})
+}
+
+// formatText formats s in the Prometheus text exposition format.
+func formatText(s gctuner.Snapshot) []byte {
+	var buf bytes.Buffer
+
+	enabled := uint64(0)
+	if s.Enabled {
+		enabled = 1
+	}
+
+	writeMetric(&buf, "gctuner_enabled", "gauge", "Whether the GC tuner is running.", enabled)
+	writeMetric(&buf, "gctuner_gc_percent", "gauge", "Current GC percent.", uint64(s.GCPercent))
+	writeMetric(&buf, "gctuner_min_gc_percent", "gauge", "Minimum GC percent bound.", uint64(s.MinGCPercent))
+	writeMetric(&buf, "gctuner_max_gc_percent", "gauge", "Maximum GC percent bound.", uint64(s.MaxGCPercent))
+	writeMetric(&buf, "gctuner_threshold_bytes", "gauge", "Heap threshold targeted by the tuner.", s.Threshold)
+	writeMetric(&buf, "gctuner_memory_limit_bytes", "gauge", "Go runtime memory limit applied by the last tuning.", s.MemoryLimit)
+	writeMetric(&buf, "gctuner_heap_inuse_bytes", "gauge", "Heap in use observed by the last tuning.", s.HeapInuse)
+	writeMetric(&buf, "gctuner_heap_goal_bytes", "gauge", "Runtime heap goal observed by the last tuning.", s.HeapGoal)
+	writeMetric(&buf, "gctuner_nonheap_bytes", "gauge", "Memory outside of the Go heap reported by accountants in the last tuning.", s.NonHeap)
+	writeMetric(&buf, "gctuner_tunings_total", "counter", "Tuning steps since the tuner was enabled.", s.Tunings)
+
+	writeHeader(&buf, "gctuner_clamps_total", "counter", "Tuning steps whose GC percent was clamped to a bound.")
+	writeSample(&buf, "gctuner_clamps_total", `bound="min"`, s.MinClamps)
+	writeSample(&buf, "gctuner_clamps_total", `bound="max"`, s.MaxClamps)
+
+	writeMetric(&buf, "gctuner_pressure_level", "gauge", "Pressure level: 0 none, 1 high, 2 emergency.", uint64(s.PressureLevel))
+	writeMetric(&buf, "gctuner_emergencies_total", "counter", "Times the tuner entered the emergency level.", s.Emergencies)
+
+	if !s.LastTuning.IsZero() {
+		writeHeader(&buf, "gctuner_last_tuning_timestamp_seconds", "gauge", "Unix time of the last tuning step.")
+		buf.WriteString("gctuner_last_tuning_timestamp_seconds ")
+		buf.WriteString(strconv.FormatFloat(float64(lastTuningUnixNano(s))/1e9, 'f', -1, 64))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+func writeMetric(buf *bytes.Buffer, name, typ, help string, value uint64) {
+	writeHeader(buf, name, typ, help)
+	writeSample(buf, name, "", value)
+}
+
+func writeHeader(buf *bytes.Buffer, name, typ, help string) {
+	buf.WriteString("# HELP ")
+	buf.WriteString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(help)
+	buf.WriteString("\n# TYPE ")
+	buf.WriteString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(typ)
+	buf.WriteByte('\n')
+}
+
+func writeSample(buf *bytes.Buffer, name, labels string, value uint64) {
+	buf.WriteString(name)
+	if labels != "" {
+		buf.WriteByte('{')
+		buf.WriteString(labels)
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(strconv.FormatUint(value, 10))
+	buf.WriteByte('\n')
+}
+
+func lastTuningUnixNano(s gctuner.Snapshot) int64 {
+	if s.LastTuning.IsZero() {
+		return 0
+	}
+
+	return s.LastTuning.UnixNano()
+}
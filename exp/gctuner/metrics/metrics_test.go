@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.dw1.io/x/exp/gctuner"
+)
+
+func TestFormatText(t *testing.T) {
+	s := gctuner.Snapshot{
+		Enabled:       true,
+		Threshold:     1024,
+		MemoryLimit:   2048,
+		HeapInuse:     512,
+		HeapGoal:      768,
+		NonHeap:       256,
+		GCPercent:     100,
+		MinGCPercent:  50,
+		MaxGCPercent:  500,
+		Tunings:       7,
+		MinClamps:     2,
+		MaxClamps:     3,
+		PressureLevel: gctuner.PressureHigh,
+		Emergencies:   4,
+		LastTuning:    time.Unix(1700000000, 500000000),
+	}
+
+	got := string(formatText(s))
+	for _, want := range []string{
+		"# TYPE gctuner_gc_percent gauge\ngctuner_gc_percent 100\n",
+		"gctuner_enabled 1\n",
+		"gctuner_threshold_bytes 1024\n",
+		"gctuner_memory_limit_bytes 2048\n",
+		"gctuner_heap_inuse_bytes 512\n",
+		"gctuner_heap_goal_bytes 768\n",
+		"gctuner_nonheap_bytes 256\n",
+		"# TYPE gctuner_tunings_total counter\ngctuner_tunings_total 7\n",
+		"gctuner_clamps_total{bound=\"min\"} 2\n",
+		"gctuner_clamps_total{bound=\"max\"} 3\n",
+		"gctuner_pressure_level 1\n",
+		"# TYPE gctuner_emergencies_total counter\ngctuner_emergencies_total 4\n",
+		"gctuner_last_tuning_timestamp_seconds 1700000000.5\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %q in:\n%s", want, got)
+		}
+	}
+
+	if strings.Count(got, "# TYPE gctuner_clamps_total") != 1 {
+		t.Fatalf("expected one TYPE line for gctuner_clamps_total:\n%s", got)
+	}
+
+	got = string(formatText(gctuner.Snapshot{}))
+	if strings.Contains(got, "gctuner_last_tuning_timestamp_seconds") {
+		t.Fatalf("expected no last tuning timestamp before any tuning:\n%s", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != contentType {
+		t.Fatalf("expected content type %q, got %q", contentType, ct)
+	}
+
+	if !strings.Contains(rec.Body.String(), "gctuner_gc_percent ") {
+		t.Fatalf("unexpected body:\n%s", rec.Body.String())
+	}
+}
+
+func TestVar(t *testing.T) {
+	Publish()
+	Publish()
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(Var().String()), &got); err != nil {
+		t.Fatalf("unexpected error decoding expvar: %v", err)
+	}
+
+	if _, ok := got["gc_percent"]; !ok {
+		t.Fatalf("missing gc_percent in %v", got)
+	}
+}
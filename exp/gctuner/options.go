@@ -1,13 +1,87 @@
 package gctuner
 
+import "time"
+
 type options struct {
+	threshold *int64
+
+	source     MemorySource
+	sourceSet  bool
+	runtime    Runtime
+	runtimeSet bool
+
+	limitSource    func() uint64
+	limitSourceSet bool
+
 	minGCPercent *uint32
 	maxGCPercent *uint32
+
+	onTune    func(Decision)
+	onTuneSet bool
+
+	strategy    Strategy
+	strategySet bool
+
+	runtimeAccountant *bool
+
+	pressure *pressureThresholds
+
+	traceSize *int
+
+	emergency    *Emergency
+	emergencySet bool
+
+	limitRefresh     *time.Duration
+	onLimitChange    func(LimitChange)
+	onLimitChangeSet bool
 }
 
-// Option configures behavior for [Enable].
+// Option configures behavior for [Enable] and [Tuner].
 type Option func(*options)
 
+// WithThreshold sets the heap threshold of a [Tuner], in bytes. -1 derives
+// it from the memory limit and 0 disables tuning; see [Enable]. The default
+// is -1. [Enable] sets it from its threshold argument.
+func WithThreshold(threshold int64) Option {
+	return func(o *options) {
+		o.threshold = &threshold
+	}
+}
+
+// WithMemorySource sets the [MemorySource] a [Tuner] reads the heap and
+// memory limit from. The default is the current process. A nil source is
+// invalid.
+func WithMemorySource(src MemorySource) Option {
+	return func(o *options) {
+		o.source = src
+		o.sourceSet = true
+	}
+}
+
+// WithLimitSource adds fn as a memory limit source of a [Tuner], such as a
+// limit read from a configuration service. fn returns the limit in bytes, or
+// 0 if it is unknown. The effective memory limit is the lowest of all
+// sources, see [Tuner.DetectLimit].
+//
+// fn is called whenever the limit is detected, including on every
+// [WithLimitRefresh] interval, so it must be fast and safe for concurrent
+// use. A nil fn removes a previously set one.
+func WithLimitSource(fn func() uint64) Option {
+	return func(o *options) {
+		o.limitSource = fn
+		o.limitSourceSet = true
+	}
+}
+
+// WithRuntime sets the [Runtime] a [Tuner] applies its decisions to. The
+// default is the Go runtime. A nil runtime is invalid.
+func WithRuntime(rt Runtime) Option {
+	return func(o *options) {
+		o.runtime = rt
+		o.runtimeSet = true
+	}
+}
+
 // WithMinGCPercent sets the minimum GC percent used by the tuner.
 // Values of 0 are invalid.
 func WithMinGCPercent(percent uint32) Option {
@@ -23,3 +97,117 @@ func WithMaxGCPercent(percent uint32) Option {
 		o.maxGCPercent = &percent
 	}
 }
+
+// WithOnTune sets a callback invoked with every tuning [Decision].
+//
+// The callback runs on the runtime finalizer goroutine after each GC cycle,
+// so it must be fast and must not block. A nil callback removes a previously
+// set one.
+func WithOnTune(fn func(Decision)) Option {
+	return func(o *options) {
+		o.onTune = fn
+		o.onTuneSet = true
+	}
+}
+
+// WithTraceSize sets the number of recent decisions kept for [Tuner.Trace].
+// The default is [DefaultTraceSize]. 0 turns the trace off, and negative
+// values are invalid.
+func WithTraceSize(n int) Option {
+	return func(o *options) {
+		o.traceSize = &n
+	}
+}
+
+// WithStrategy sets the [Strategy] that computes the GC percent. The default
+// is [LinearStrategy]. A nil strategy is invalid.
+func WithStrategy(s Strategy) Option {
+	return func(o *options) {
+		o.strategy = s
+		o.strategySet = true
+	}
+}
+
+// WithRuntimeAccountant sets whether the tuner accounts for the memory the Go
+// runtime holds outside of the heap, such as goroutine stacks and GC
+// metadata, read from runtime/metrics. The default is false.
+//
+// Like the accountants registered with [Tuner.RegisterAccountant], this
+// memory is subtracted from the threshold. It is not subtracted from the Go
+// memory limit, which already covers it.
+func WithRuntimeAccountant(enabled bool) Option {
+	return func(o *options) {
+		o.runtimeAccountant = &enabled
+	}
+}
+
+type pressureThresholds struct {
+	some float64
+	full float64
+}
+
+// WithMemoryPressure makes the tuner react to Linux memory Pressure Stall
+// Information, read from /proc/pressure/memory and from the memory.pressure
+// file of the process cgroup, whichever is higher.
+//
+// some and full are thresholds for the 10-second "some" and "full" stall
+// averages, in percent (0-100). A threshold of 0 ignores that line. When an
+// average crosses its threshold, the GC percent is lowered toward the
+// minimum, further the more the threshold is exceeded, and it relaxes again
+// as pressure clears. WithMemoryPressure(0, 0) turns this off.
+//
+// Pressure catches what the heap threshold misses, such as page cache and
+// sibling processes in the same cgroup. Where PSI is unavailable, it has no
+// effect.
+func WithMemoryPressure(some, full float64) Option {
+	return func(o *options) {
+		o.pressure = &pressureThresholds{some: some, full: full}
+	}
+}
+
+// WithEmergency enables the emergency tier, for when the heap in use keeps
+// growing past the threshold while the GC percent is already at its
+// minimum.
+//
+// The tuner then tracks a [PressureLevel]: [PressureHigh] once the heap in
+// use stays at or above the threshold for several GC cycles, and
+// [PressureEmergency] once it crosses a second watermark above the
+// threshold. On every level change, the callbacks registered with
+// [Tuner.OnPressure] are called, so applications can drop caches or reject
+// requests. On entering [PressureEmergency], memory is also returned to the
+// OS with [debug.FreeOSMemory], if the [Runtime] supports it. Levels are
+// left with hysteresis as the heap in use falls back below the threshold.
+//
+// A nil e turns the emergency tier off. See [Emergency] for the defaults.
+func WithEmergency(e *Emergency) Option {
+	return func(o *options) {
+		o.emergency = nil
+		if e != nil {
+			c := *e
+			o.emergency = &c
+		}
+		o.emergencySet = true
+	}
+}
+
+// WithLimitRefresh re-detects the effective memory limit every interval.
+//
+// When the limit changes, for example after a Kubernetes in-place pod resize
+// or a systemd property change, a [SetMemLimitPercent] override is recomputed
+// from its percent, and a threshold derived with [Enable](-1) is derived
+// again. An interval of 0 stops refreshing. Negative values are invalid.
+func WithLimitRefresh(interval time.Duration) Option {
+	return func(o *options) {
+		o.limitRefresh = &interval
+	}
+}
+
+// WithOnLimitChange sets a callback invoked when [WithLimitRefresh] detects
+// a change of the effective memory limit. A nil callback removes a
+// previously set one.
+func WithOnLimitChange(fn func(LimitChange)) Option {
+	return func(o *options) {
+		o.onLimitChange = fn
+		o.onLimitChangeSet = true
+	}
+}
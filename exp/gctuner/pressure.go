@@ -0,0 +1,136 @@
+package gctuner
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.dw1.io/x/exp/gctuner/internal/cgroup"
+)
+
+// pressureReadInterval bounds how often PSI files are read. The kernel
+// updates the averages every 2 seconds, so reading them on every GC cycle
+// gains nothing.
+const pressureReadInterval = time.Second
+
+// pressure holds the avg10 stall percentages of a PSI file.
+type pressure struct {
+	some float64
+	full float64
+}
+
+// parsePressure parses a PSI file such as /proc/pressure/memory:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func parsePressure(data string) (pressure, bool) {
+	var (
+		p  pressure
+		ok bool
+	)
+
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "avg10=") {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimPrefix(fields[1], "avg10="), 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "some":
+			p.some, ok = v, true
+		case "full":
+			p.full, ok = v, true
+		}
+	}
+
+	return p, ok
+}
+
+// readPressure reads the system-wide and cgroup memory PSI and returns the
+// higher stall percentages of the two.
+func readPressure() pressure {
+	var p pressure
+
+	if data, err := ioutil.ReadFile("/proc/pressure/memory"); err == nil {
+		if sys, ok := parsePressure(string(data)); ok {
+			p = sys
+		}
+	}
+
+	if data, err := cgroup.GetMemoryPressure(); err == nil {
+		if cg, ok := parsePressure(data); ok {
+			if cg.some > p.some {
+				p.some = cg.some
+			}
+			if cg.full > p.full {
+				p.full = cg.full
+			}
+		}
+	}
+
+	return p
+}
+
+// pressureMonitor lowers the GC percent when memory pressure crosses its
+// thresholds.
+type pressureMonitor struct {
+	some float64 // avg10 "some" threshold, 0 to ignore
+	full float64 // avg10 "full" threshold, 0 to ignore
+	read func() pressure
+
+	mu       sync.Mutex
+	last     pressure
+	lastRead time.Time
+}
+
+func newPressureMonitor(some, full float64) *pressureMonitor {
+	return &pressureMonitor{some: some, full: full, read: readPressure}
+}
+
+// sample returns the current pressure, reading it at most once per
+// pressureReadInterval.
+func (m *pressureMonitor) sample(now time.Time) pressure {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.lastRead.IsZero() || now.Sub(m.lastRead) >= pressureReadInterval {
+		m.last = m.read()
+		m.lastRead = now
+	}
+
+	return m.last
+}
+
+// ratio returns how far p is past the thresholds: below 1 is under both
+// thresholds, 1 is at a threshold, 2 is twice a threshold, and so on.
+func (m *pressureMonitor) ratio(p pressure) float64 {
+	var r float64
+
+	if m.some > 0 && p.some/m.some > r {
+		r = p.some / m.some
+	}
+
+	if m.full > 0 && p.full/m.full > r {
+		r = p.full / m.full
+	}
+
+	return r
+}
+
+// lowerGCPercent moves percent toward minPercent by ratio. At a ratio of 1,
+// half of the distance to minPercent is removed, at 2 three quarters, and so
+// on. Below 1, percent is returned unchanged.
+func lowerGCPercent(percent, minPercent uint32, ratio float64) uint32 {
+	if ratio < 1 || percent <= minPercent {
+		return percent
+	}
+
+	return minPercent + uint32(float64(percent-minPercent)/(2*ratio))
+}
@@ -0,0 +1,144 @@
+package gctuner
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestParsePressure(t *testing.T) {
+	p, ok := parsePressure(`some avg10=12.50 avg60=3.00 avg300=1.00 total=123
+full avg10=4.25 avg60=1.00 avg300=0.50 total=45
+`)
+	if !ok || p.some != 12.5 || p.full != 4.25 {
+		t.Fatalf("unexpected pressure: %+v ok=%v", p, ok)
+	}
+
+	if _, ok := parsePressure("garbage\n"); ok {
+		t.Fatal("expected parse failure for invalid data")
+	}
+}
+
+func TestLowerGCPercent(t *testing.T) {
+	cases := []struct {
+		percent uint32
+		ratio   float64
+		want    uint32
+	}{
+		{500, 0, 500},
+		{500, 0.99, 500},
+		{500, 1, 275},
+		{500, 2, 162},
+		{50, 4, 50},
+	}
+
+	for _, tc := range cases {
+		if got := lowerGCPercent(tc.percent, 50, tc.ratio); got != tc.want {
+			t.Fatalf("lowerGCPercent(%d, 50, %g) = %d, want %d", tc.percent, tc.ratio, got, tc.want)
+		}
+	}
+}
+
+func TestPressureMonitor(t *testing.T) {
+	reads := 0
+	m := newPressureMonitor(10, 0)
+	m.read = func() pressure {
+		reads++
+		return pressure{some: 20, full: 50}
+	}
+
+	now := time.Now()
+	p := m.sample(now)
+	m.sample(now.Add(pressureReadInterval / 2))
+	if reads != 1 {
+		t.Fatalf("expected one read within the interval, got %d", reads)
+	}
+
+	m.sample(now.Add(pressureReadInterval))
+	if reads != 2 {
+		t.Fatalf("expected a second read after the interval, got %d", reads)
+	}
+
+	// full is ignored with a zero threshold.
+	if got := m.ratio(p); got != 2 {
+		t.Fatalf("expected ratio 2, got %g", got)
+	}
+}
+
+func TestTunerPressure(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	prev := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(prev)
+
+	tn := newTuner(4 << 30)
+	tn.Stop() // drive apply by hand, without GC-triggered tuning
+
+	current := pressure{}
+	m := newPressureMonitor(10, 5)
+	m.read = func() pressure { return current }
+	tn.setPressure(m)
+
+	var got Decision
+	tn.setOnTune(func(d Decision) { got = d })
+
+	tn.apply(1<<30, 0, 4<<30, 0, 0)
+	if got.GCPercent != 300 {
+		t.Fatalf("expected gc percent 300 without pressure, got %+v", got)
+	}
+
+	current = pressure{some: 20, full: 5}
+	m.lastRead = time.Time{}
+	tn.apply(1<<30, 0, 4<<30, 0, 0)
+	if got.GCPercent != 112 || got.PressureSome != 20 || got.PressureFull != 5 {
+		t.Fatalf("expected gc percent 112 under pressure, got %+v", got)
+	}
+
+	// Lowering a percent just above the minimum lands on it, which counts as
+	// a min clamp.
+	m.lastRead = time.Time{}
+	tn.apply(2844349202, 0, 4<<30, 0, 0)
+	if minPercent := tn.MinGCPercent(); got.GCPercent != minPercent || !got.ClampedToMin {
+		t.Fatalf("expected gc percent clamped to min %d under pressure, got %+v", minPercent, got)
+	}
+	if s := tn.Stats(); s.MinClamps != 1 {
+		t.Fatalf("expected one min clamp, got %+v", s)
+	}
+
+	current = pressure{}
+	m.lastRead = time.Time{}
+	tn.apply(1<<30, 0, 4<<30, 0, 0)
+	if got.GCPercent != 300 {
+		t.Fatalf("expected gc percent to relax to 300, got %+v", got)
+	}
+}
+
+func TestMemoryPressureValidation(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	if err := Enable(1024, WithMemoryPressure(-1, 0)); err == nil {
+		t.Fatal("expected error for negative pressure threshold")
+	}
+
+	if err := Enable(1024, WithMemoryPressure(0, 101)); err == nil {
+		t.Fatal("expected error for pressure threshold above 100")
+	}
+
+	if err := Enable(1024, WithMemoryPressure(10, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m, _ := std.pressure.Load().(*pressureMonitor); m == nil || m.some != 10 {
+		t.Fatalf("expected pressure monitor with some=10, got %+v", m)
+	}
+
+	if err := Enable(1024, WithMemoryPressure(0, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if m, _ := std.pressure.Load().(*pressureMonitor); m != nil {
+		t.Fatalf("expected pressure monitor to be removed, got %+v", m)
+	}
+}
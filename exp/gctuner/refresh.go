@@ -0,0 +1,107 @@
+package gctuner
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// LimitChange describes a change of the effective memory limit detected by
+// [WithLimitRefresh].
+type LimitChange struct {
+	// Time is when the change was detected.
+	Time time.Time
+	// OldLimit and NewLimit are the effective memory limits, in bytes.
+	OldLimit uint64
+	NewLimit uint64
+	// OldThreshold and NewThreshold are the tuner thresholds, in bytes. They
+	// are equal unless the threshold is derived with [Enable](-1).
+	OldThreshold uint64
+	NewThreshold uint64
+}
+
+// startRefresh restarts the refresh loop with the configured interval. It
+// must be called with t.mu held.
+func (t *Tuner) startRefresh() {
+	t.stopRefresh()
+
+	if t.refresh <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	t.refreshStop = stop
+
+	go func(interval time.Duration) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				t.refreshLimit(stop)
+			}
+		}
+	}(t.refresh)
+}
+
+// stopRefresh stops the refresh loop, if any. It must be called with t.mu
+// held.
+func (t *Tuner) stopRefresh() {
+	if t.refreshStop != nil {
+		close(t.refreshStop)
+		t.refreshStop = nil
+	}
+}
+
+// refreshLimit re-detects the effective memory limit and, if it changed,
+// updates the override and threshold derived from it.
+func (t *Tuner) refreshLimit(stop chan struct{}) {
+	t.mu.Lock()
+
+	select {
+	case <-stop:
+		t.mu.Unlock()
+
+		return
+	default:
+	}
+
+	limit := t.memoryLimit()
+	if limit == 0 || limit == t.limit {
+		t.mu.Unlock()
+
+		return
+	}
+
+	change := LimitChange{
+		Time:         time.Now(),
+		OldLimit:     t.limit,
+		NewLimit:     limit,
+		OldThreshold: t.getThreshold(),
+	}
+	t.limit = limit
+
+	if atomic.LoadUint32(&t.memLimitOverrideSet) != 0 {
+		percent := math.Float64frombits(atomic.LoadUint64(&t.memLimitOverridePercent))
+		if override := t.MemLimitPercent(percent); override != 0 {
+			atomic.StoreUint64(&t.memLimitOverride, override)
+		}
+	}
+
+	if t.derived {
+		if threshold, err := t.resolveThreshold(-1); err == nil {
+			t.setThreshold(threshold)
+		}
+	}
+
+	change.NewThreshold = t.getThreshold()
+	fn := t.onLimitChange
+	t.mu.Unlock()
+
+	if fn != nil {
+		fn(change)
+	}
+}
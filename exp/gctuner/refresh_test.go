@@ -0,0 +1,103 @@
+package gctuner
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimitRefresh(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	mem := &fakeMemory{limit: 1 << 30}
+	std.source.Store(sourceHolder{mem})
+
+	SetMemLimitPercent(50)
+
+	changes := make(chan LimitChange, 1)
+	err := Enable(-1,
+		WithLimitRefresh(time.Millisecond),
+		WithOnLimitChange(func(c LimitChange) { changes <- c }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error enabling tuner: %v", err)
+	}
+
+	if got := Stats().Threshold; got != 1<<29 {
+		t.Fatalf("expected threshold %d, got %d", 1<<29, got)
+	}
+
+	atomic.StoreUint64(&mem.limit, 4<<30)
+
+	select {
+	case c := <-changes:
+		if c.OldLimit != 1<<30 || c.NewLimit != 4<<30 {
+			t.Fatalf("unexpected limits in change: %+v", c)
+		}
+
+		if c.OldThreshold != 1<<29 || c.NewThreshold != 2<<30 {
+			t.Fatalf("unexpected thresholds in change: %+v", c)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a limit change")
+	}
+
+	if override, ok := std.getMemLimitOverride(); !ok || override != 2<<30 {
+		t.Fatalf("expected override %d, got %d (set=%v)", uint64(2<<30), override, ok)
+	}
+
+	if got := Stats().Threshold; got != 2<<30 {
+		t.Fatalf("expected threshold %d, got %d", uint64(2<<30), got)
+	}
+}
+
+func TestLimitRefreshExplicitThreshold(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	mem := &fakeMemory{limit: 1 << 30}
+	std.source.Store(sourceHolder{mem})
+
+	changes := make(chan LimitChange, 1)
+	err := Enable(1<<20,
+		WithLimitRefresh(time.Millisecond),
+		WithOnLimitChange(func(c LimitChange) { changes <- c }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error enabling tuner: %v", err)
+	}
+
+	atomic.StoreUint64(&mem.limit, 2<<30)
+
+	select {
+	case c := <-changes:
+		if c.OldThreshold != 1<<20 || c.NewThreshold != 1<<20 {
+			t.Fatalf("expected explicit threshold to be kept, got %+v", c)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a limit change")
+	}
+
+	// Disabling the tuner stops the refresh loop.
+	if err := Enable(0); err != nil {
+		t.Fatalf("unexpected error disabling tuner: %v", err)
+	}
+
+	atomic.StoreUint64(&mem.limit, 3<<30)
+
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected change after disable: %+v", c)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestLimitRefreshValidation(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	if err := Enable(1024, WithLimitRefresh(-time.Second)); err == nil {
+		t.Fatal("expected error for negative refresh interval")
+	}
+}
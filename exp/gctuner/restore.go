@@ -0,0 +1,109 @@
+package gctuner
+
+import "sync/atomic"
+
+// runtimeSettings are the runtime settings a tuner changes.
+type runtimeSettings struct {
+	gcPercent      int
+	memoryLimit    int64
+	hasMemoryLimit bool // false on Go < 1.19
+}
+
+// Disable stops the default tuner and restores the GC percent and memory
+// limit that were in effect before it first changed them. It is the same as
+// [Enable](0). The configuration is kept for the next [Enable].
+func Disable() {
+	std.Stop()
+}
+
+// Reset disables the default tuner like [Disable], and returns its
+// configuration to the defaults. See [Tuner.Reset].
+func Reset() {
+	std.Reset()
+}
+
+// Reset stops the tuner like [Tuner.Stop], and returns its configuration
+// to the defaults of [New] with no options: options, memory limit override,
+// accountants, pressure callbacks, and stats. A closed tuner stays closed.
+func (t *Tuner) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stop()
+	t.setDefaults()
+	t.resetStats()
+}
+
+// setDefaults sets the configuration of t to its defaults. It must be called
+// with t.mu held, or before t is shared.
+func (t *Tuner) setDefaults() {
+	t.thresholdArg = -1
+	t.derived = false
+	t.limit = 0
+	t.refresh = 0
+	t.onLimitChange = nil
+
+	atomic.StoreUint32(&t.minGCPercent, defaultMinGCPercent)
+	atomic.StoreUint32(&t.maxGCPercent, defaultMaxGCPercent)
+	atomic.StoreUint32(&t.memLimitOverrideSet, 0)
+	atomic.StoreUint64(&t.memLimitOverride, 0)
+	atomic.StoreUint64(&t.memLimitOverridePercent, 0)
+	atomic.StoreUint32(&t.gcPercent, getDefaultGCPercent())
+	atomic.StoreUint64(&t.threshold, 0)
+	atomic.StoreUint32(&t.runtimeAccountant, 0)
+	atomic.StoreUint32(&t.level, uint32(PressureNone))
+
+	t.source.Store(sourceHolder{processMemory{}})
+	t.runtime.Store(runtimeHolder{processRuntime{}})
+	t.onTune.Store(onTuneFunc(nil))
+	t.pressure.Store((*pressureMonitor)(nil))
+	t.strategy.Store(strategyHolder{LinearStrategy{}})
+	t.emergency.Store((*emergencyMonitor)(nil))
+	t.limitSource.Store(limitSourceFunc(nil))
+	t.accountants.Store(accountants(nil))
+	t.pressureCallbacks.Store(pressureCallbacks(nil))
+
+	t.statsMu.Lock()
+	t.trace = newTraceRing(DefaultTraceSize)
+	t.statsMu.Unlock()
+}
+
+// saveRuntime records the runtime settings in effect, unless they are
+// already recorded, so that restoreRuntime can put them back. It must be
+// called with t.mu held.
+func (t *Tuner) saveRuntime() {
+	if t.saved != nil {
+		return
+	}
+
+	rt := t.getRuntime()
+
+	// SetGCPercent is the only way to read the GC percent.
+	gcPercent := rt.SetGCPercent(int(getDefaultGCPercent()))
+	rt.SetGCPercent(gcPercent)
+
+	s := &runtimeSettings{gcPercent: gcPercent}
+	s.memoryLimit, s.hasMemoryLimit = readMemoryLimit(rt)
+	t.saved = s
+}
+
+// restoreRuntime restores the runtime settings recorded by saveRuntime. It
+// must be called with t.mu held.
+func (t *Tuner) restoreRuntime() {
+	s := t.saved
+	if s == nil {
+		return
+	}
+
+	t.saved = nil
+
+	rt := t.getRuntime()
+	rt.SetGCPercent(s.gcPercent)
+	if s.hasMemoryLimit {
+		rt.SetMemoryLimit(s.memoryLimit)
+	}
+
+	if s.gcPercent >= 0 {
+		atomic.StoreUint32(&t.gcPercent, uint32(s.gcPercent))
+	}
+}
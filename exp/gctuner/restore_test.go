@@ -0,0 +1,175 @@
+package gctuner
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTunerStopRestoresRuntime(t *testing.T) {
+	rt := &fakeRuntime{gcPercent: 150, memoryLimit: 1 << 40}
+
+	tn, err := New(
+		WithMemorySource(&fakeMemory{inuse: 1 << 30}),
+		WithRuntime(rt),
+		WithThreshold(4<<30),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	_, hasMemoryLimit := readMemoryLimit(rt)
+
+	for i := 0; i < 3; i++ {
+		if err := tn.Start(); err != nil {
+			t.Fatalf("unexpected error starting tuner: %v", err)
+		}
+
+		tn.Tune()
+		if got := atomic.LoadInt64(&rt.gcPercent); got != 300 {
+			t.Fatalf("expected tuned gc percent 300, got %d", got)
+		}
+
+		if got := atomic.LoadInt64(&rt.memoryLimit); hasMemoryLimit && got != 4<<30 {
+			t.Fatalf("expected tuned memory limit %d, got %d", int64(4<<30), got)
+		}
+
+		tn.Stop()
+		if got := atomic.LoadInt64(&rt.gcPercent); got != 150 {
+			t.Fatalf("cycle %d: expected restored gc percent 150, got %d", i, got)
+		}
+
+		if got := atomic.LoadInt64(&rt.memoryLimit); got != 1<<40 {
+			t.Fatalf("cycle %d: expected restored memory limit %d, got %d", i, int64(1<<40), got)
+		}
+
+		if got := tn.GCPercent(); got != 150 {
+			t.Fatalf("cycle %d: expected gc percent 150 after stop, got %d", i, got)
+		}
+	}
+}
+
+func TestTunerRestartReusesFinalizer(t *testing.T) {
+	prev := debug.SetGCPercent(-1)
+	defer debug.SetGCPercent(prev)
+
+	tn, err := New(
+		WithMemorySource(&fakeMemory{inuse: 1 << 30}),
+		WithRuntime(&fakeRuntime{gcPercent: 100}),
+		WithThreshold(4<<30),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	if err := tn.Start(); err != nil {
+		t.Fatalf("unexpected error starting tuner: %v", err)
+	}
+	f := tn.finalizer
+
+	for i := 0; i < 100; i++ {
+		tn.Stop()
+		if err := tn.Start(); err != nil {
+			t.Fatalf("unexpected error starting tuner: %v", err)
+		}
+	}
+
+	if tn.finalizer != f {
+		t.Fatal("expected restarts to reuse the pending finalizer chain")
+	}
+}
+
+func TestTunerReset(t *testing.T) {
+	rt := &fakeRuntime{gcPercent: 100}
+
+	tn, err := New(
+		WithMemorySource(&fakeMemory{inuse: 1 << 30, limit: 8 << 30}),
+		WithRuntime(rt),
+		WithThreshold(4<<30),
+		WithMinGCPercent(20),
+		WithEmergency(&Emergency{}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	if err := tn.RegisterAccountant("mmap", func() uint64 { return 1 }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tn.Start(); err != nil {
+		t.Fatalf("unexpected error starting tuner: %v", err)
+	}
+	tn.Tune()
+
+	tn.Reset()
+
+	if tn.Running() {
+		t.Fatal("expected reset tuner to be stopped")
+	}
+
+	if got := atomic.LoadInt64(&rt.gcPercent); got != 100 {
+		t.Fatalf("expected restored gc percent 100, got %d", got)
+	}
+
+	if tn.MinGCPercent() != defaultMinGCPercent || tn.Threshold() != 0 || len(tn.Accountants()) != 0 {
+		t.Fatalf("expected default configuration, got min %d, threshold %d, accountants %v",
+			tn.MinGCPercent(), tn.Threshold(), tn.Accountants())
+	}
+
+	if _, ok := tn.getRuntime().(processRuntime); !ok {
+		t.Fatalf("expected default runtime, got %T", tn.getRuntime())
+	}
+
+	if s := tn.Stats(); s.Tunings != 0 {
+		t.Fatalf("expected reset stats, got %+v", s)
+	}
+}
+
+func TestDisable(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	rt := &fakeRuntime{gcPercent: 120}
+	if err := Enable(4096, WithRuntime(rt), WithMemorySource(&fakeMemory{inuse: 1024})); err != nil {
+		t.Fatalf("unexpected error enabling tuner: %v", err)
+	}
+
+	std.Tune()
+	if got := atomic.LoadInt64(&rt.gcPercent); got == 120 {
+		t.Fatal("expected tuned gc percent")
+	}
+
+	Disable()
+
+	if Stats().Enabled {
+		t.Fatal("expected disabled tuner")
+	}
+
+	if got := atomic.LoadInt64(&rt.gcPercent); got != 120 {
+		t.Fatalf("expected restored gc percent 120, got %d", got)
+	}
+
+	// Enable(0) restores as well.
+	if err := Enable(4096); err != nil {
+		t.Fatalf("unexpected error enabling tuner: %v", err)
+	}
+	std.Tune()
+
+	if err := Enable(0); err != nil {
+		t.Fatalf("unexpected error disabling tuner: %v", err)
+	}
+
+	if got := atomic.LoadInt64(&rt.gcPercent); got != 120 {
+		t.Fatalf("expected restored gc percent 120, got %d", got)
+	}
+
+	Reset()
+
+	if GetMinGCPercent() != defaultMinGCPercent {
+		t.Fatalf("expected default min gc percent, got %d", GetMinGCPercent())
+	}
+}
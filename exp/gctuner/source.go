@@ -0,0 +1,68 @@
+package gctuner
+
+import "runtime/debug"
+
+// MemorySource reports the memory state a [Tuner] works from.
+type MemorySource interface {
+	// Heap returns the heap in use and the runtime heap goal, in bytes. The
+	// goal may be 0 if unknown.
+	Heap() (inuse, goal uint64)
+	// MemoryLimit returns the memory limit thresholds are derived from, in
+	// bytes, or 0 if it is unknown.
+	MemoryLimit() uint64
+}
+
+// CPUSource is implemented by a [MemorySource] that also reports CPU time,
+// which [GCCPUStrategy] steers by. The default source implements it on Go
+// 1.20 and newer.
+type CPUSource interface {
+	// CPUTime returns the cumulative CPU time spent on GC and available to
+	// the process, in seconds. Both are 0 if unknown.
+	CPUTime() (gc, total float64)
+}
+
+// Runtime applies the decisions of a [Tuner]. Its methods have the
+// semantics of [debug.SetGCPercent] and debug.SetMemoryLimit.
+//
+// On Go < 1.19, SetMemoryLimit is never called. If the Runtime also has a
+// FreeOSMemory() method, it is called on entering [PressureEmergency].
+type Runtime interface {
+	SetGCPercent(percent int) int
+	SetMemoryLimit(limit int64) int64
+}
+
+// processMemory is the default MemorySource: the heap of the current process
+// and the lowest of its memory limits, see [Tuner.DetectLimit].
+type processMemory struct{}
+
+func (processMemory) Heap() (uint64, uint64) {
+	return readHeap()
+}
+
+func (processMemory) MemoryLimit() uint64 {
+	return lowestLimit(processLimits()).Limit
+}
+
+func (processMemory) MemoryLimits() []Limit {
+	return processLimits()
+}
+
+func (processMemory) CPUTime() (float64, float64) {
+	return readCPUTime()
+}
+
+// processRuntime is the default Runtime: the Go runtime of the current
+// process.
+type processRuntime struct{}
+
+func (processRuntime) SetGCPercent(percent int) int {
+	return debug.SetGCPercent(percent)
+}
+
+func (processRuntime) SetMemoryLimit(limit int64) int64 {
+	return setRuntimeMemoryLimit(limit)
+}
+
+func (processRuntime) FreeOSMemory() {
+	debug.FreeOSMemory()
+}
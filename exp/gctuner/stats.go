@@ -0,0 +1,93 @@
+package gctuner
+
+import "time"
+
+// Decision describes a single tuning step taken after a GC cycle.
+type Decision struct {
+	// Time is when the decision was made.
+	Time time.Time
+	// HeapInuse is the heap in use observed after the GC cycle, in bytes.
+	HeapInuse uint64
+	// HeapGoal is the runtime heap goal observed after the GC cycle, in
+	// bytes.
+	HeapGoal uint64
+	// Threshold is the heap threshold the decision targets, in bytes, after
+	// NonHeap was subtracted.
+	Threshold uint64
+	// NonHeap is the memory used outside of the Go heap, as reported by the
+	// accountants, in bytes. See [Tuner.RegisterAccountant].
+	NonHeap uint64
+	// MemoryLimit is the Go runtime memory limit applied, in bytes. It is 0
+	// on Go < 1.19.
+	MemoryLimit uint64
+	// PrevGCPercent is the GC percent before the decision.
+	PrevGCPercent uint32
+	// GCPercent is the GC percent applied by the decision.
+	GCPercent uint32
+	// MinGCPercent and MaxGCPercent are the bounds in effect.
+	MinGCPercent uint32
+	MaxGCPercent uint32
+	// ClampedToMin and ClampedToMax report whether GCPercent was clamped to
+	// one of the bounds.
+	ClampedToMin bool
+	ClampedToMax bool
+	// PressureSome and PressureFull are the 10-second memory stall averages,
+	// in percent, when [WithMemoryPressure] is set.
+	PressureSome float64
+	PressureFull float64
+	// PressureLevel is the level tracked by [WithEmergency] after the
+	// decision.
+	PressureLevel PressureLevel
+}
+
+// Snapshot is a point-in-time view of the tuner state returned by [Stats]
+// and [Tuner.Stats].
+type Snapshot struct {
+	// Enabled reports whether the tuner is running.
+	Enabled bool
+	// Threshold is the current heap threshold, in bytes.
+	Threshold uint64
+	// MemoryLimit is the memory limit applied by the last tuning, in bytes.
+	// It is 0 on Go < 1.19.
+	MemoryLimit uint64
+	// HeapInuse is the heap in use observed by the last tuning, in bytes.
+	HeapInuse uint64
+	// HeapGoal is the runtime heap goal observed by the last tuning, in bytes.
+	HeapGoal uint64
+	// NonHeap is the memory used outside of the Go heap reported by the
+	// accountants in the last tuning, in bytes.
+	NonHeap uint64
+	// GCPercent is the current GC percent.
+	GCPercent uint32
+	// MinGCPercent and MaxGCPercent are the current bounds.
+	MinGCPercent uint32
+	MaxGCPercent uint32
+	// Tunings is the number of tuning steps since the tuner was enabled.
+	Tunings uint64
+	// MinClamps and MaxClamps count the tuning steps whose GC percent was
+	// clamped to MinGCPercent or MaxGCPercent.
+	MinClamps uint64
+	MaxClamps uint64
+	// LastTuning is when the last tuning step ran. It is zero if none ran.
+	LastTuning time.Time
+	// PressureLevel is the level tracked by [WithEmergency] after the last
+	// tuning.
+	PressureLevel PressureLevel
+	// Emergencies counts the times the tuner entered [PressureEmergency]
+	// since it was enabled.
+	Emergencies uint64
+}
+
+// Stats returns a snapshot of the tuner state.
+func (t *Tuner) Stats() Snapshot {
+	s := Snapshot{
+		Enabled:      t.Running(),
+		Threshold:    t.getThreshold(),
+		GCPercent:    t.getGCPercent(),
+		MinGCPercent: t.MinGCPercent(),
+		MaxGCPercent: t.MaxGCPercent(),
+	}
+	t.fillStats(&s)
+
+	return s
+}
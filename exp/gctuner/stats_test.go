@@ -0,0 +1,107 @@
+package gctuner
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestStatsWhenDisabled(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	s := Stats()
+	if s.Enabled || s.Tunings != 0 || s.Threshold != 0 {
+		t.Fatalf("unexpected stats for disabled tuner: %+v", s)
+	}
+
+	if s.MinGCPercent != GetMinGCPercent() || s.MaxGCPercent != GetMaxGCPercent() {
+		t.Fatalf("expected bounds %d/%d, got %d/%d", GetMinGCPercent(), GetMaxGCPercent(), s.MinGCPercent, s.MaxGCPercent)
+	}
+}
+
+func TestStatsAndOnTune(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	prev := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(prev)
+
+	decisions := make(chan Decision, 1)
+	onTune := func(d Decision) {
+		select {
+		case decisions <- d:
+		default:
+		}
+	}
+
+	const threshold = 1 << 40
+	if err := Enable(threshold, WithOnTune(onTune), WithMaxGCPercent(400)); err != nil {
+		t.Fatalf("unexpected error enabling tuner: %v", err)
+	}
+
+	var d Decision
+	deadline := time.After(10 * time.Second)
+	for got := false; !got; {
+		runtime.GC()
+		select {
+		case d = <-decisions:
+			got = true
+		case <-deadline:
+			t.Fatal("timed out waiting for a tuning decision")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if d.Threshold != threshold || d.HeapInuse == 0 || d.Time.IsZero() {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+
+	if d.GCPercent != 400 || !d.ClampedToMax || d.ClampedToMin || d.MaxGCPercent != 400 {
+		t.Fatalf("expected decision clamped to max 400, got %+v", d)
+	}
+
+	s := Stats()
+	if !s.Enabled || s.Threshold != threshold || s.Tunings == 0 || s.LastTuning.IsZero() {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	if s.GCPercent != 400 || s.HeapInuse == 0 {
+		t.Fatalf("unexpected stats after tuning: %+v", s)
+	}
+
+	// A later Enable without WithOnTune keeps the callback; WithOnTune(nil)
+	// removes it.
+	if err := Enable(threshold, WithOnTune(nil)); err != nil {
+		t.Fatalf("unexpected error updating tuner: %v", err)
+	}
+
+	if fn, _ := std.onTune.Load().(onTuneFunc); fn != nil {
+		t.Fatal("expected WithOnTune(nil) to remove the callback")
+	}
+}
+
+func TestCalcGCPercentBounded(t *testing.T) {
+	cases := []struct {
+		name       string
+		inuse      uint64
+		threshold  uint64
+		want       uint32
+		clampedMin bool
+		clampedMax bool
+	}{
+		{"within-bounds", 1 << 30, 4 << 30, 300, false, false},
+		{"over-threshold", 5 << 30, 4 << 30, 50, true, false},
+		{"below-min", 3 << 30, 4 << 30, 50, true, false},
+		{"above-max", 1, 4 << 30, 500, false, true},
+		{"overflow", 1, ^uint64(0), 500, false, true},
+	}
+
+	for _, tc := range cases {
+		got, clampedMin, clampedMax := calcGCPercentBounded(tc.inuse, tc.threshold, 50, 500)
+		if got != tc.want || clampedMin != tc.clampedMin || clampedMax != tc.clampedMax {
+			t.Fatalf("%s: got (%d, %v, %v), want (%d, %v, %v)", tc.name, got, clampedMin, clampedMax, tc.want, tc.clampedMin, tc.clampedMax)
+		}
+	}
+}
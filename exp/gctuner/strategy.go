@@ -0,0 +1,371 @@
+package gctuner
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// Input is the state a [Strategy] computes the GC percent from.
+type Input struct {
+	// Time is when the tuning step runs.
+	Time time.Time
+	// HeapInuse is the heap in use after the GC cycle, in bytes.
+	HeapInuse uint64
+	// HeapGoal is the runtime heap goal after the GC cycle, in bytes.
+	HeapGoal uint64
+	// Threshold is the heap threshold, in bytes.
+	Threshold uint64
+	// GCPercent is the GC percent currently in effect.
+	GCPercent uint32
+	// MinGCPercent and MaxGCPercent are the bounds the result is clamped to.
+	MinGCPercent uint32
+	MaxGCPercent uint32
+	// GCCPUSeconds and TotalCPUSeconds are the cumulative CPU time spent on
+	// GC and available to the process, in seconds, if the [MemorySource]
+	// is a [CPUSource]. Both are 0 if unknown.
+	GCCPUSeconds    float64
+	TotalCPUSeconds float64
+}
+
+// Strategy computes the GC percent for a tuning step.
+//
+// GCPercent is called after every GC cycle with a non-zero heap in use and
+// threshold. The tuner clamps the result to the GC percent bounds. Calls are
+// serialized by the tuner, but a Strategy used by more than one tuner must be
+// safe for concurrent use.
+type Strategy interface {
+	GCPercent(in Input) uint32
+}
+
+// LinearStrategy is the default strategy. It solves
+//
+//	threshold = inuse + inuse * (gcPercent / 100)
+//
+// for the GC percent on every cycle, without state. Under bursty allocation,
+// it can swing between the bounds from one cycle to the next.
+type LinearStrategy struct{}
+
+// GCPercent implements [Strategy].
+func (LinearStrategy) GCPercent(in Input) uint32 {
+	return saturateUint32(linearGCPercent(in.HeapInuse, in.Threshold))
+}
+
+// DefaultEWMAAlpha is the smoothing factor used when EWMAStrategy.Alpha is 0.
+const DefaultEWMAAlpha = 0.2
+
+// EWMAStrategy applies the linear formula to an exponentially weighted
+// moving average of the heap in use, and keeps the current GC percent while
+// the new value stays within a hysteresis band around it.
+//
+// A heap in use at or above the threshold always yields the minimum, so
+// smoothing never delays the reaction to running out of room.
+//
+// The zero value is usable. An EWMAStrategy must not be copied after first
+// use.
+type EWMAStrategy struct {
+	// Alpha is the weight of the newest sample, in (0, 1]. 0 means
+	// DefaultEWMAAlpha, and 1 disables smoothing.
+	Alpha float64
+	// Hysteresis is the relative change from the current GC percent below
+	// which the current value is kept, such as 0.2 for 20%. 0 disables it.
+	Hysteresis float64
+
+	mu      sync.Mutex
+	avg     float64
+	started bool
+}
+
+// GCPercent implements [Strategy].
+func (s *EWMAStrategy) GCPercent(in Input) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alpha := s.Alpha
+	if alpha == 0 {
+		alpha = DefaultEWMAAlpha
+	}
+
+	if !s.started {
+		s.avg = float64(in.HeapInuse)
+		s.started = true
+	} else {
+		s.avg = alpha*float64(in.HeapInuse) + (1-alpha)*s.avg
+	}
+
+	if in.HeapInuse >= in.Threshold {
+		return in.MinGCPercent
+	}
+
+	avg := uint64(s.avg)
+	if avg == 0 {
+		avg = 1
+	}
+
+	percent, _, _ := clampGCPercent(linearGCPercent(avg, in.Threshold), in.MinGCPercent, in.MaxGCPercent)
+
+	current := float64(in.GCPercent)
+	if s.Hysteresis > 0 && math.Abs(float64(percent)-current) <= s.Hysteresis*current {
+		return in.GCPercent
+	}
+
+	return percent
+}
+
+func (s *EWMAStrategy) validate() error {
+	if s.Alpha < 0 || s.Alpha > 1 || math.IsNaN(s.Alpha) {
+		return fmt.Errorf("invalid EWMA alpha: %g", s.Alpha)
+	}
+
+	if s.Hysteresis < 0 || math.IsNaN(s.Hysteresis) {
+		return fmt.Errorf("invalid EWMA hysteresis: %g", s.Hysteresis)
+	}
+
+	return nil
+}
+
+// Defaults used by PIDStrategy when its fields are 0.
+const (
+	DefaultPIDTarget = 0.9
+	DefaultPIDKp     = 0.2
+	DefaultPIDKi     = 0.5
+)
+
+// PIDStrategy is a PID controller that steers the peak heap toward a
+// fraction of the threshold.
+//
+// The peak heap is predicted as inuse * (1 + gcPercent / 100) for the
+// current GC percent, and the error is its distance from Target * threshold,
+// relative to the latter. The controller works in velocity form: each step
+// adds
+//
+//	100 * (Kp*(e-e1) + Ki*e + Kd*(e-2*e1+e2))
+//
+// to the current GC percent, where e1 and e2 are the previous errors. As the
+// tuner clamps the result, the integral term cannot wind up.
+//
+// The zero value is usable. A PIDStrategy must not be copied after first use.
+type PIDStrategy struct {
+	// Target is the fraction of the threshold to steer the peak heap to, in
+	// (0, 1]. 0 means DefaultPIDTarget.
+	Target float64
+	// Kp, Ki, and Kd are the proportional, integral, and derivative gains.
+	// If all three are 0, DefaultPIDKp and DefaultPIDKi are used.
+	Kp float64
+	Ki float64
+	Kd float64
+
+	mu      sync.Mutex
+	e1, e2  float64
+	out     float64 // unrounded last output
+	started bool
+}
+
+// GCPercent implements [Strategy].
+func (s *PIDStrategy) GCPercent(in Input) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.Target
+	if target == 0 {
+		target = DefaultPIDTarget
+	}
+
+	kp, ki, kd := s.Kp, s.Ki, s.Kd
+	if kp == 0 && ki == 0 && kd == 0 {
+		kp, ki = DefaultPIDKp, DefaultPIDKi
+	}
+
+	setpoint := target * float64(in.Threshold)
+	peak := float64(in.HeapInuse) * (1 + float64(in.GCPercent)/100)
+	e := (setpoint - peak) / setpoint
+
+	if !s.started {
+		s.e1, s.e2 = e, e
+		s.started = true
+	}
+
+	delta := kp*(e-s.e1) + ki*e + kd*(e-2*s.e1+s.e2)
+	s.e2, s.e1 = s.e1, e
+
+	// Continue from the unrounded output while the tuner applied it as is,
+	// so small corrections are not lost to rounding.
+	base := float64(in.GCPercent)
+	if math.Abs(s.out-base) < 0.5 {
+		base = s.out
+	}
+
+	percent := base + 100*delta
+	if percent <= 0 || math.IsNaN(percent) {
+		s.out = 0
+		return 0
+	}
+
+	if percent >= math.MaxUint32 {
+		s.out = math.MaxUint32
+		return math.MaxUint32
+	}
+
+	s.out = percent
+
+	return uint32(percent + 0.5)
+}
+
+func (s *PIDStrategy) validate() error {
+	if s.Target < 0 || s.Target > 1 || math.IsNaN(s.Target) {
+		return fmt.Errorf("invalid PID target: %g", s.Target)
+	}
+
+	for _, k := range []float64{s.Kp, s.Ki, s.Kd} {
+		if k < 0 || math.IsNaN(k) || math.IsInf(k, 0) {
+			return fmt.Errorf("invalid PID gains: kp=%g ki=%g kd=%g", s.Kp, s.Ki, s.Kd)
+		}
+	}
+
+	return nil
+}
+
+// DefaultGCCPUTarget is the GC CPU budget used when GCCPUStrategy.Target is
+// 0.
+const DefaultGCCPUTarget = 0.1
+
+// gcCPUMaxStep bounds the factor GCCPUStrategy changes the GC percent by in
+// one step, so a noisy cycle cannot swing it far.
+const gcCPUMaxStep = 2.0
+
+// GCCPUStrategy steers the GC percent toward a GC CPU budget rather than a
+// heap size, for latency-sensitive services that care more about the CPU
+// spent on GC than about heap headroom.
+//
+// On every cycle, it measures the fraction of the available CPU time spent
+// on GC since the previous cycle, from Input.GCCPUSeconds and
+// Input.TotalCPUSeconds, and scales the current GC percent by that fraction
+// over Target. As the GC CPU cost is roughly inversely proportional to the GC
+// percent, the GC percent rises when GC exceeds the budget, and falls to keep
+// the heap small when GC stays under it. Each step changes the GC percent by
+// at most a factor of 2, and a fraction within Hysteresis of Target keeps it
+// as is.
+//
+// The threshold is a hard ceiling: the result never exceeds what
+// [LinearStrategy] returns, and a heap in use at or above the threshold
+// yields the minimum. Until the CPU time is known, such as on the first
+// cycle, on Go < 1.20, or with a [MemorySource] that is not a [CPUSource],
+// the current GC percent is kept under that ceiling.
+//
+// The zero value is usable. A GCCPUStrategy must not be copied after first
+// use.
+type GCCPUStrategy struct {
+	// Target is the GC CPU budget, as a fraction of the available CPU time,
+	// in (0, 1), such as 0.1 for 10%. 0 means DefaultGCCPUTarget.
+	Target float64
+	// Hysteresis is the relative distance from Target within which the GC
+	// percent is kept, such as 0.1 for 10%. 0 disables it.
+	Hysteresis float64
+
+	mu        sync.Mutex
+	gc, total float64 // CPU time seen by the previous step
+}
+
+// GCPercent implements [Strategy].
+func (s *GCCPUStrategy) GCPercent(in Input) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.Target
+	if target == 0 {
+		target = DefaultGCCPUTarget
+	}
+
+	gc, total := in.GCCPUSeconds-s.gc, in.TotalCPUSeconds-s.total
+	known := s.total > 0 && total > 0 && gc >= 0
+	s.gc, s.total = in.GCCPUSeconds, in.TotalCPUSeconds
+
+	if in.HeapInuse >= in.Threshold {
+		return in.MinGCPercent
+	}
+
+	percent := float64(in.GCPercent)
+	if known {
+		ratio := gc / total / target
+		if math.Abs(ratio-1) > s.Hysteresis {
+			percent *= math.Max(1/gcCPUMaxStep, math.Min(ratio, gcCPUMaxStep))
+		}
+	}
+
+	if ceiling := linearGCPercent(in.HeapInuse, in.Threshold); percent >= float64(ceiling) {
+		return saturateUint32(ceiling)
+	}
+
+	return uint32(percent + 0.5)
+}
+
+func (s *GCCPUStrategy) validate() error {
+	if s.Target < 0 || s.Target >= 1 || math.IsNaN(s.Target) {
+		return fmt.Errorf("invalid GC CPU target: %g", s.Target)
+	}
+
+	if s.Hysteresis < 0 || math.IsNaN(s.Hysteresis) {
+		return fmt.Errorf("invalid GC CPU hysteresis: %g", s.Hysteresis)
+	}
+
+	return nil
+}
+
+// strategyHolder wraps a Strategy so it can be stored in an atomic.Value.
+type strategyHolder struct {
+	Strategy
+}
+
+// validateStrategy validates the built-in strategies' parameters.
+func validateStrategy(s Strategy) error {
+	if s == nil {
+		return fmt.Errorf("invalid strategy: nil")
+	}
+
+	if v, ok := s.(interface{ validate() error }); ok {
+		return v.validate()
+	}
+
+	return nil
+}
+
+// linearGCPercent returns (threshold - inuse) / inuse * 100, or 0 if inuse is
+// at or above threshold. It saturates at math.MaxUint64. inuse must not be 0.
+func linearGCPercent(inuse, threshold uint64) uint64 {
+	if threshold <= inuse {
+		return 0
+	}
+
+	diff := threshold - inuse
+	hi, lo := bits.Mul64(diff, 100)
+	if hi >= inuse {
+		// quotient overflows uint64
+		return math.MaxUint64
+	}
+
+	q, _ := bits.Div64(hi, lo, inuse)
+
+	return q
+}
+
+// clampGCPercent clamps percent to [minPercent, maxPercent] and reports
+// whether it was clamped to minPercent or maxPercent.
+func clampGCPercent(percent uint64, minPercent, maxPercent uint32) (uint32, bool, bool) {
+	if percent < uint64(minPercent) {
+		return minPercent, true, false
+	} else if percent > uint64(maxPercent) {
+		return maxPercent, false, true
+	}
+
+	return uint32(percent), false, false
+}
+
+func saturateUint32(n uint64) uint32 {
+	if n > math.MaxUint32 {
+		return math.MaxUint32
+	}
+
+	return uint32(n)
+}
@@ -0,0 +1,282 @@
+package gctuner
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestLinearStrategy(t *testing.T) {
+	const gb = 1 << 30
+
+	cases := []struct {
+		inuse, threshold uint64
+		want             uint32
+	}{
+		{1 * gb, 4 * gb, 300},
+		{2 * gb, 4 * gb, 100},
+		{4 * gb, 4 * gb, 0},
+		{1, ^uint64(0), ^uint32(0)},
+	}
+
+	for _, tc := range cases {
+		got := LinearStrategy{}.GCPercent(Input{HeapInuse: tc.inuse, Threshold: tc.threshold})
+		if got != tc.want {
+			t.Fatalf("LinearStrategy(%d, %d) = %d, want %d", tc.inuse, tc.threshold, got, tc.want)
+		}
+	}
+}
+
+// swings counts the changes of direction of a GC percent series.
+func swings(series []uint32) int {
+	n := 0
+	for i := 2; i < len(series); i++ {
+		a, b := int64(series[i-1])-int64(series[i-2]), int64(series[i])-int64(series[i-1])
+		if a*b < 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func TestEWMAStrategySmoothsBursts(t *testing.T) {
+	const threshold = 1000
+
+	// bursty live heap alternating between 100 and 400 of 1000
+	heap := make([]uint64, 40)
+	for i := range heap {
+		heap[i] = 100
+		if i%2 == 1 {
+			heap[i] = 400
+		}
+	}
+
+	run := func(s Strategy) []uint32 {
+		percent := uint32(100)
+		var out []uint32
+		for _, inuse := range heap {
+			raw := s.GCPercent(Input{HeapInuse: inuse, Threshold: threshold, GCPercent: percent, MinGCPercent: 50, MaxGCPercent: 500})
+			percent, _, _ = clampGCPercent(uint64(raw), 50, 500)
+			out = append(out, percent)
+		}
+
+		return out
+	}
+
+	linear := run(LinearStrategy{})
+	ewma := run(&EWMAStrategy{Hysteresis: 0.2})
+
+	if swings(linear) < 30 {
+		t.Fatalf("expected the linear strategy to swing, got %v", linear)
+	}
+
+	if n := swings(ewma[10:]); n != 0 {
+		t.Fatalf("expected the EWMA strategy to settle, got %d swings in %v", n, ewma)
+	}
+
+	// Reaching the threshold yields the minimum immediately.
+	s := &EWMAStrategy{}
+	s.GCPercent(Input{HeapInuse: 100, Threshold: threshold, GCPercent: 100, MinGCPercent: 50, MaxGCPercent: 500})
+	if got := s.GCPercent(Input{HeapInuse: threshold, Threshold: threshold, GCPercent: 500, MinGCPercent: 50, MaxGCPercent: 500}); got != 50 {
+		t.Fatalf("expected min gc percent at the threshold, got %d", got)
+	}
+}
+
+func TestPIDStrategyConverges(t *testing.T) {
+	const (
+		threshold = 1000
+		live      = 200
+	)
+
+	s := &PIDStrategy{Target: 0.8}
+	percent := uint32(100)
+	for i := 0; i < 200; i++ {
+		raw := s.GCPercent(Input{HeapInuse: live, Threshold: threshold, GCPercent: percent, MinGCPercent: 50, MaxGCPercent: 500})
+		percent, _, _ = clampGCPercent(uint64(raw), 50, 500)
+	}
+
+	// peak = live * (1 + percent/100) = 0.8 * threshold => percent = 300
+	if percent < 299 || percent > 301 {
+		t.Fatalf("expected gc percent near 300, got %d", percent)
+	}
+}
+
+// gcCPUModel feeds GCCPUStrategy a GC CPU cost inversely proportional to
+// the GC percent: cost / percent of the CPU time of every cycle.
+type gcCPUModel struct {
+	cost      float64
+	gc, total float64
+}
+
+func (m *gcCPUModel) input(percent uint32, inuse, threshold uint64) Input {
+	m.gc += m.cost / float64(percent)
+	m.total++
+
+	return Input{
+		HeapInuse:       inuse,
+		Threshold:       threshold,
+		GCPercent:       percent,
+		MinGCPercent:    50,
+		MaxGCPercent:    1000,
+		GCCPUSeconds:    m.gc,
+		TotalCPUSeconds: m.total,
+	}
+}
+
+func TestGCCPUStrategyConverges(t *testing.T) {
+	// 40% of the CPU on GC at GC percent 100, so a 10% budget needs 400.
+	m := &gcCPUModel{cost: 40}
+	s := &GCCPUStrategy{Target: 0.1}
+
+	var series []uint32
+	percent := uint32(100)
+	for i := 0; i < 20; i++ {
+		raw := s.GCPercent(m.input(percent, 1<<20, 1<<40))
+		percent, _, _ = clampGCPercent(uint64(raw), 50, 1000)
+		series = append(series, percent)
+	}
+
+	if percent != 400 {
+		t.Fatalf("expected gc percent 400, got %d (%v)", percent, series)
+	}
+
+	// Each step at most doubles the GC percent.
+	if series[0] != 100 || series[1] != 200 {
+		t.Fatalf("expected the first steps to be bounded, got %v", series)
+	}
+
+	// Under budget, the GC percent falls to keep the heap small.
+	m.cost = 5
+	for i := 0; i < 20; i++ {
+		raw := s.GCPercent(m.input(percent, 1<<20, 1<<40))
+		percent, _, _ = clampGCPercent(uint64(raw), 50, 1000)
+	}
+
+	if percent != 50 {
+		t.Fatalf("expected gc percent 50, got %d", percent)
+	}
+}
+
+func TestGCCPUStrategyThresholdCeiling(t *testing.T) {
+	m := &gcCPUModel{cost: 40}
+	s := &GCCPUStrategy{}
+
+	// The budget asks for more, but the threshold allows 150 at most.
+	percent := uint32(100)
+	for i := 0; i < 10; i++ {
+		raw := s.GCPercent(m.input(percent, 400, 1000))
+		percent, _, _ = clampGCPercent(uint64(raw), 50, 1000)
+	}
+
+	if percent != 150 {
+		t.Fatalf("expected gc percent capped at 150, got %d", percent)
+	}
+
+	if got := s.GCPercent(m.input(percent, 1000, 1000)); got != 50 {
+		t.Fatalf("expected the minimum at the threshold, got %d", got)
+	}
+}
+
+func TestGCCPUStrategyUnknownCPU(t *testing.T) {
+	s := &GCCPUStrategy{}
+
+	for _, tc := range []struct {
+		inuse uint64
+		want  uint32
+	}{
+		{200, 120}, // the current GC percent is kept
+		{500, 100}, // under the ceiling
+	} {
+		got := s.GCPercent(Input{HeapInuse: tc.inuse, Threshold: 1000, GCPercent: 120, MinGCPercent: 50, MaxGCPercent: 500})
+		if got != tc.want {
+			t.Fatalf("inuse %d: expected %d, got %d", tc.inuse, tc.want, got)
+		}
+	}
+}
+
+func TestStrategyValidation(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	for _, s := range []Strategy{
+		nil,
+		&EWMAStrategy{Alpha: 1.5},
+		&EWMAStrategy{Hysteresis: -1},
+		&PIDStrategy{Target: 2},
+		&PIDStrategy{Kp: -1},
+		&GCCPUStrategy{Target: 1},
+		&GCCPUStrategy{Target: -0.1},
+		&GCCPUStrategy{Hysteresis: -1},
+	} {
+		if err := Enable(1024, WithStrategy(s)); err == nil {
+			t.Fatalf("expected error for strategy %+v", s)
+		}
+	}
+}
+
+func TestTunerStrategy(t *testing.T) {
+	cleanup := saveAndResetState(t)
+	defer cleanup()
+
+	prev := debug.SetGCPercent(100)
+	defer debug.SetGCPercent(prev)
+
+	tn := newTuner(4 << 30)
+	tn.Stop() // drive apply by hand, without GC-triggered tuning
+
+	var got Decision
+	tn.setOnTune(func(d Decision) { got = d })
+	tn.setStrategy(strategyFunc(func(Input) uint32 { return 1000 }))
+
+	tn.apply(1<<30, 0, 4<<30, 0, 0)
+	if got.GCPercent != defaultMaxGCPercent || !got.ClampedToMax {
+		t.Fatalf("expected strategy output clamped to max, got %+v", got)
+	}
+}
+
+// cpuMemory is a fakeMemory that also reports CPU time.
+type cpuMemory struct {
+	fakeMemory
+	gc, total float64
+}
+
+func (m *cpuMemory) CPUTime() (float64, float64) {
+	return m.gc, m.total
+}
+
+func TestTunerCPUSource(t *testing.T) {
+	var got Input
+	tn, err := New(
+		WithMemorySource(&cpuMemory{fakeMemory: fakeMemory{inuse: 1 << 30}, gc: 1.5, total: 12}),
+		WithRuntime(&fakeRuntime{gcPercent: 100}),
+		WithThreshold(4<<30),
+		WithStrategy(strategyFunc(func(in Input) uint32 {
+			got = in
+			return 100
+		})),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	tn.Tune()
+	if got.GCCPUSeconds != 1.5 || got.TotalCPUSeconds != 12 {
+		t.Fatalf("expected the CPU time of the source, got %+v", got)
+	}
+
+	if err := tn.Update(WithMemorySource(&fakeMemory{inuse: 1 << 30})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tn.Tune()
+	if got.GCCPUSeconds != 0 || got.TotalCPUSeconds != 0 {
+		t.Fatalf("expected unknown CPU time, got %+v", got)
+	}
+}
+
+type strategyFunc func(Input) uint32
+
+func (f strategyFunc) GCPercent(in Input) uint32 {
+	return f(in)
+}
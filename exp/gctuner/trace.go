@@ -0,0 +1,78 @@
+package gctuner
+
+// DefaultTraceSize is the number of decisions kept by [Tuner.Trace] unless
+// [WithTraceSize] is set.
+const DefaultTraceSize = 256
+
+// Trace returns the recent decisions of the default tuner. See
+// [Tuner.Trace].
+func Trace() []Decision {
+	return std.Trace()
+}
+
+// Trace returns the most recent tuning decisions, oldest first, up to the
+// size set with [WithTraceSize]. The trace is cleared when the tuner starts
+// and kept after it stops, so it can be inspected after the fact.
+func (t *Tuner) Trace() []Decision {
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	return t.trace.decisions()
+}
+
+// traceRing is a bounded ring buffer of decisions.
+type traceRing struct {
+	buf  []Decision
+	next int // index of the next write
+	full bool
+}
+
+func newTraceRing(size int) traceRing {
+	return traceRing{buf: make([]Decision, size)}
+}
+
+func (r *traceRing) add(d Decision) {
+	if len(r.buf) == 0 {
+		return
+	}
+
+	r.buf[r.next] = d
+	r.next++
+	if r.next == len(r.buf) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+// decisions returns a copy of the recorded decisions, oldest first.
+func (r *traceRing) decisions() []Decision {
+	if !r.full {
+		return append([]Decision(nil), r.buf[:r.next]...)
+	}
+
+	out := make([]Decision, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+
+	return append(out, r.buf[:r.next]...)
+}
+
+// resize sets the capacity to size, keeping the most recent decisions.
+func (r *traceRing) resize(size int) {
+	if size == len(r.buf) {
+		return
+	}
+
+	old := r.decisions()
+	if len(old) > size {
+		old = old[len(old)-size:]
+	}
+
+	*r = newTraceRing(size)
+	for _, d := range old {
+		r.add(d)
+	}
+}
+
+func (r *traceRing) reset() {
+	*r = newTraceRing(len(r.buf))
+}
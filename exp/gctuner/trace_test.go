@@ -0,0 +1,93 @@
+package gctuner
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTraceRing(t *testing.T) {
+	r := newTraceRing(3)
+	if got := r.decisions(); len(got) != 0 {
+		t.Fatalf("expected empty trace, got %v", got)
+	}
+
+	for i := uint64(1); i <= 5; i++ {
+		r.add(Decision{HeapInuse: i})
+	}
+
+	assertTrace(t, r.decisions(), 3, 4, 5)
+
+	r.resize(5)
+	r.add(Decision{HeapInuse: 6})
+	assertTrace(t, r.decisions(), 3, 4, 5, 6)
+
+	r.resize(2)
+	assertTrace(t, r.decisions(), 5, 6)
+
+	r.reset()
+	assertTrace(t, r.decisions())
+
+	r.resize(0)
+	r.add(Decision{HeapInuse: 7})
+	assertTrace(t, r.decisions())
+}
+
+func assertTrace(t *testing.T, got []Decision, want ...uint64) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d decisions, got %d: %+v", len(want), len(got), got)
+	}
+
+	for i, d := range got {
+		if d.HeapInuse != want[i] {
+			t.Fatalf("decision %d: expected heap inuse %d, got %d", i, want[i], d.HeapInuse)
+		}
+	}
+}
+
+func TestTunerTrace(t *testing.T) {
+	mem := &fakeMemory{inuse: 1 << 30}
+
+	tn, err := New(
+		WithMemorySource(mem),
+		WithRuntime(&fakeRuntime{gcPercent: 100}),
+		WithThreshold(4<<30),
+		WithTraceSize(2),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	for _, inuse := range []uint64{1 << 30, 2 << 30, 4 << 30} {
+		atomic.StoreUint64(&mem.inuse, inuse)
+		tn.Tune()
+	}
+
+	trace := tn.Trace()
+	assertTrace(t, trace, 2<<30, 4<<30)
+
+	if d := trace[1]; d.GCPercent != 50 || !d.ClampedToMin || d.Threshold != 4<<30 || d.PrevGCPercent != 100 {
+		t.Fatalf("unexpected last decision: %+v", d)
+	}
+
+	// The trace is cleared on start and kept after stop.
+	prev := debug.SetGCPercent(-1)
+	defer debug.SetGCPercent(prev)
+
+	if err := tn.Start(); err != nil {
+		t.Fatalf("unexpected error starting tuner: %v", err)
+	}
+	tn.Tune()
+	tn.Stop()
+
+	if got := tn.Trace(); len(got) != 1 {
+		t.Fatalf("expected 1 decision after restart, got %d", len(got))
+	}
+
+	if _, err := New(WithTraceSize(-1)); err == nil {
+		t.Fatal("expected error for negative trace size")
+	}
+}
@@ -1,29 +1,29 @@
 package gctuner
 
 import (
+	"errors"
 	"fmt"
 	"math"
-	"math/bits"
 	"os"
-	"runtime/debug"
 	"strconv"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
-var (
-	maxGCPercent uint32 = 500
-	minGCPercent uint32 = 50
+const (
+	defaultMinGCPercent uint32 = 50
+	defaultMaxGCPercent uint32 = 500
+)
 
+var (
 	defaultGCPercent uint32 = 100
 	defaultGCOnce    sync.Once
-
-	tunerMu sync.Mutex
-
-	memLimitOverride    uint64
-	memLimitOverrideSet uint32
 )
 
+// ErrClosed is returned when starting or updating a closed [Tuner].
+var ErrClosed = errors.New("gctuner: tuner closed")
+
 func getDefaultGCPercent() uint32 {
 	defaultGCOnce.Do(func() {
 		defaultGCPercent = readGOGC()
@@ -41,137 +41,231 @@ func readGOGC() uint32 {
 	return uint32(gogc)
 }
 
-// Enable starts or updates GC tuning with the given threshold and options.
+/*
+	Heap
+
+________________  => limit: host/cgroup memory hard limit
+|               |
+|---------------| => threshold: increase GCPercent when gc_trigger < threshold
+|               |
+|---------------| => gc_trigger: heap_live + heap_live * GCPercent / 100
+|               |
+|---------------|
+|   heap_live   |
+|_______________|
+
+Go runtime only trigger GC when hit gc_trigger which affected by GCPercent and heap_live.
+So we can change GCPercent dynamically to tuning GC performance.
+*/
+
+// Tuner adjusts the GC percent so the GC trigger stays near a heap
+// threshold.
 //
-// Threshold semantics:
-//   - 0 disables the tuner
-//   - -1 derives the threshold from the effective memory limit (or a
-//     [SetMemLimitPercent] override, if set)
-//   - >0 uses the provided byte value directly
+// A Tuner reads memory from a [MemorySource] and applies its decisions to a
+// [Runtime]. By default these are the current process and the Go runtime,
+// but both can be replaced with [WithMemorySource] and [WithRuntime], for
+// example to unit-test tuning with [Tuner.Tune] without triggering GC.
 //
-// Enable returns an error if the threshold cannot be resolved (for -1), or if
-// any provided options are invalid.
-func Enable(threshold int64, opts ...Option) error {
-	tunerMu.Lock()
-	defer tunerMu.Unlock()
+// Only one started Tuner should drive the Go runtime of a process. The
+// package-level functions, such as [Enable], use a default instance.
+type Tuner struct {
+	// 64-bit fields accessed atomically come first, so they are 8-byte
+	// aligned on 32-bit platforms. Read by the tuning step.
+	memLimitOverride        uint64
+	memLimitOverridePercent uint64 // math.Float64bits of the percent
+	threshold               uint64 // high water level, in bytes
+
+	mu sync.Mutex // guards the lifecycle and the fields below
+
+	thresholdArg  int64  // requested threshold, see [WithThreshold]
+	derived       bool   // threshold derived from the memory limit
+	limit         uint64 // memory limit seen by the last refresh
+	refresh       time.Duration
+	refreshStop   chan struct{}
+	onLimitChange func(LimitChange)
+	finalizer     *finalizer // non-nil while started
+	idle          *finalizer // stopped finalizer that may resume
+	saved         *runtimeSettings
+	closed        bool
+
+	// read by the tuning step
+	minGCPercent        uint32
+	maxGCPercent        uint32
+	memLimitOverrideSet uint32
+	gcPercent           uint32
+	runtimeAccountant   uint32 // see [WithRuntimeAccountant]
+	level               uint32 // PressureLevel
+
+	source   atomic.Value // sourceHolder
+	runtime  atomic.Value // runtimeHolder
+	onTune   atomic.Value // onTuneFunc
+	pressure atomic.Value // *pressureMonitor
+	strategy atomic.Value // strategyHolder
+
+	emergency   atomic.Value // *emergencyMonitor
+	limitSource atomic.Value // limitSourceFunc
+
+	accountants       atomic.Value // accountants, written with mu held
+	pressureCallbacks atomic.Value // pressureCallbacks, written with mu held
+
+	statsMu   sync.Mutex
+	last      Decision
+	tunings   uint64
+	minClamps uint64
+	maxClamps uint64
+
+	emergencies uint64
+	trace       traceRing
+}
 
-	cfg := &options{}
-	for _, opt := range opts {
-		if opt != nil {
-			opt(cfg)
-		}
-	}
+type (
+	sourceHolder  struct{ MemorySource }
+	runtimeHolder struct{ Runtime }
+	onTuneFunc    func(Decision)
+)
 
-	if err := validateOptions(cfg); err != nil {
-		return err
-	}
+// New returns a Tuner configured with opts. The tuner does nothing until
+// [Tuner.Start] or [Tuner.Tune] is called.
+//
+// The threshold defaults to -1 (derived from the memory limit, see
+// [WithThreshold]). New returns an error if the options are invalid or the
+// threshold cannot be resolved.
+func New(opts ...Option) (*Tuner, error) {
+	t := newTunerInstance()
 
-	if cfg.minGCPercent != nil {
-		setMinGCPercent(*cfg.minGCPercent)
-	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	if cfg.maxGCPercent != nil {
-		setMaxGCPercent(*cfg.maxGCPercent)
+	if err := t.update(opts); err != nil {
+		return nil, err
 	}
 
-	if err := validateGCPercentRange(GetMinGCPercent(), GetMaxGCPercent()); err != nil {
-		return err
-	}
+	return t, nil
+}
 
-	resolvedThreshold, disable, err := normalizeThreshold(threshold)
-	if err != nil {
-		return err
-	}
+func newTunerInstance() *Tuner {
+	t := &Tuner{}
+	t.setDefaults()
 
-	// disable gc tuner if percent is zero
-	if disable && globalTuner != nil {
-		globalTuner.stop()
-		globalTuner = nil
+	return t
+}
 
-		return nil
+// newTuner returns a default-configured Tuner with the given threshold that
+// tunes the process on every GC.
+func newTuner(threshold uint64) *Tuner {
+	t := newTunerInstance()
+	t.thresholdArg = int64(threshold)
+	t.setThreshold(threshold)
+	t.saveRuntime()
+	t.startFinalizer() // start tuning
+
+	return t
+}
+
+// Start starts tuning after every GC cycle. Starting a started tuner is a
+// no-op. A threshold of 0 stops the tuner instead.
+func (t *Tuner) Start() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
 	}
 
-	if globalTuner == nil {
-		globalTuner = newTuner(resolvedThreshold)
+	if t.thresholdArg == 0 {
+		t.stop()
 
 		return nil
 	}
 
-	globalTuner.setThreshold(resolvedThreshold)
+	if t.finalizer == nil {
+		t.resetStats()
+		t.saveRuntime()
+		t.startFinalizer() // start tuning
+		t.startRefresh()
+	}
 
 	return nil
 }
 
-// MustEnable is like Enable but panics on error.
-func MustEnable(threshold int64, opts ...Option) {
-	if err := Enable(threshold, opts...); err != nil {
-		panic(err)
-	}
+// Stop stops tuning and restores the GC percent and memory limit that were
+// in effect before the tuner first changed them. The tuner can be started
+// again.
+func (t *Tuner) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stop()
 }
 
-func normalizeThreshold(threshold int64) (uint64, bool, error) {
-	if threshold == 0 {
-		return 0, true, nil
-	}
+// Close stops tuning like [Tuner.Stop] and releases the tuner. Later calls to [Tuner.Start]
+// and [Tuner.Update] return [ErrClosed]. Close always returns nil.
+func (t *Tuner) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	if threshold < 0 {
-		if override, ok := getMemLimitOverride(); ok {
-			return override, false, nil
-		}
+	t.stop()
+	t.closed = true
 
-		limit := GetMemLimitPercent(-1)
-		if limit == 0 {
-			return 0, false, fmt.Errorf("unable to resolve memory limit for threshold")
-		}
+	return nil
+}
 
-		return limit, false, nil
+// Update applies opts to the tuner. If [WithThreshold] is among them, the
+// threshold is resolved again. Update returns an error, leaving the
+// threshold unchanged, if the options are invalid or the threshold cannot
+// be resolved.
+func (t *Tuner) Update(opts ...Option) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrClosed
 	}
 
-	return uint64(threshold), false, nil
+	return t.update(opts)
 }
 
-// GetGCPercent returns the current effective GC percent used by the tuner.
-// If the tuner is disabled, it returns the process default (GOGC or 100).
-func GetGCPercent() uint32 {
-	tunerMu.Lock()
-	defer tunerMu.Unlock()
+// Tune runs a single tuning step now and returns its decision. It is what a
+// started tuner runs after every GC cycle, and can be used on its own to
+// drive the tuner by hand. If the threshold is 0, Tune does nothing and
+// returns a zero Decision.
+func (t *Tuner) Tune() Decision {
+	return t.tune()
+}
 
-	if globalTuner == nil {
-		return getDefaultGCPercent()
-	}
+// Running reports whether the tuner is started.
+func (t *Tuner) Running() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	return globalTuner.getGCPercent()
+	return t.finalizer != nil
 }
 
-// GetMaxGCPercent returns the current maximum GC percent allowed.
-func GetMaxGCPercent() uint32 {
-	return atomic.LoadUint32(&maxGCPercent)
+// GCPercent returns the GC percent last applied by the tuner, or the process
+// default (GOGC or 100) if it has not tuned yet.
+func (t *Tuner) GCPercent() uint32 {
+	return atomic.LoadUint32(&t.gcPercent)
 }
 
-// setMaxGCPercent sets the maximum GC percent allowed and returns the old value.
-func setMaxGCPercent(n uint32) uint32 {
-	return atomic.SwapUint32(&maxGCPercent, n)
+// MinGCPercent returns the minimum GC percent allowed.
+func (t *Tuner) MinGCPercent() uint32 {
+	return atomic.LoadUint32(&t.minGCPercent)
 }
 
-// GetMinGCPercent returns the current minimum GC percent allowed.
-func GetMinGCPercent() uint32 {
-	return atomic.LoadUint32(&minGCPercent)
+// MaxGCPercent returns the maximum GC percent allowed.
+func (t *Tuner) MaxGCPercent() uint32 {
+	return atomic.LoadUint32(&t.maxGCPercent)
 }
 
-// setMinGCPercent sets the minimum GC percent allowed and returns the old value.
-func setMinGCPercent(n uint32) uint32 {
-	return atomic.SwapUint32(&minGCPercent, n)
+// Threshold returns the resolved heap threshold, in bytes.
+func (t *Tuner) Threshold() uint64 {
+	return t.getThreshold()
 }
 
-// GetMemLimitPercent gets the memory limit based on the given percentage of the
-// detected memory limit and returns the value in bytes.
-//
-// If percent < 0, it returns the total memory limit in bytes. If percent == 0,
-// it returns 0. If percent > 100, it is clamped to 100. On Go 1.19 and newer,
-// a valid GOMEMLIMIT value (if set) overrides the detected system or cgroup
-// limit.
-func GetMemLimitPercent(percent float64) uint64 {
-	limit := getMemoryLimit()
+// MemLimitPercent returns percent of the effective memory limit of the
+// tuner, in bytes, see [Tuner.DetectLimit]. See [GetMemLimitPercent].
+func (t *Tuner) MemLimitPercent(percent float64) uint64 {
+	limit := t.memoryLimit()
 	if limit == 0 {
 		return 0
 	}
@@ -196,36 +290,169 @@ func GetMemLimitPercent(percent float64) uint64 {
 	return uint64(value)
 }
 
-// SetMemLimitPercent sets the Go memory limit based on a percentage of the
-// detected memory limit.
-//
-// On Go <= 1.19, it is a no-op. If percent resolves to 0, the override is
-// cleared. If percent > 100, it is clamped to 100.
-func SetMemLimitPercent(percent float64) {
-	limit := GetMemLimitPercent(percent)
+// SetMemLimitPercent sets the memory limit override of the tuner to percent
+// of its effective memory limit. See [SetMemLimitPercent] and
+// [Tuner.DetectLimit].
+func (t *Tuner) SetMemLimitPercent(percent float64) {
+	limit := t.MemLimitPercent(percent)
 	if limit == 0 {
-		atomic.StoreUint32(&memLimitOverrideSet, 0)
+		atomic.StoreUint32(&t.memLimitOverrideSet, 0)
 
 		return
 	}
 
-	atomic.StoreUint64(&memLimitOverride, limit)
-	atomic.StoreUint32(&memLimitOverrideSet, 1)
+	atomic.StoreUint64(&t.memLimitOverride, limit)
+	atomic.StoreUint64(&t.memLimitOverridePercent, math.Float64bits(percent))
+	atomic.StoreUint32(&t.memLimitOverrideSet, 1)
+
+	t.mu.Lock()
+	t.saveRuntime()
+	t.mu.Unlock()
 
-	setMemoryLimit(limit)
+	t.applyMemoryLimit(t.effectiveMemoryLimit(limit))
 }
 
-func getMemLimitOverride() (uint64, bool) {
-	if atomic.LoadUint32(&memLimitOverrideSet) == 0 {
+func (t *Tuner) getMemLimitOverride() (uint64, bool) {
+	if atomic.LoadUint32(&t.memLimitOverrideSet) == 0 {
 		return 0, false
 	}
 
-	return atomic.LoadUint64(&memLimitOverride), true
+	return atomic.LoadUint64(&t.memLimitOverride), true
+}
+
+// update applies opts. It must be called with t.mu held.
+func (t *Tuner) update(opts []Option) error {
+	cfg := &options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+
+	if err := t.validateOptions(cfg); err != nil {
+		return err
+	}
+
+	if cfg.source != nil {
+		t.source.Store(sourceHolder{cfg.source})
+	}
+
+	if cfg.limitSourceSet {
+		t.limitSource.Store(limitSourceFunc(cfg.limitSource))
+	}
+
+	if cfg.runtime != nil {
+		t.runtime.Store(runtimeHolder{cfg.runtime})
+	}
+
+	if cfg.minGCPercent != nil {
+		atomic.StoreUint32(&t.minGCPercent, *cfg.minGCPercent)
+	}
+
+	if cfg.maxGCPercent != nil {
+		atomic.StoreUint32(&t.maxGCPercent, *cfg.maxGCPercent)
+	}
+
+	if cfg.threshold != nil {
+		threshold, err := t.resolveThreshold(*cfg.threshold)
+		if err != nil {
+			return err
+		}
+
+		t.thresholdArg = *cfg.threshold
+		t.derived = *cfg.threshold < 0
+		t.setThreshold(threshold)
+	} else if t.thresholdArg != 0 && t.getThreshold() == 0 {
+		// first update: resolve the default threshold
+		threshold, err := t.resolveThreshold(t.thresholdArg)
+		if err != nil {
+			return err
+		}
+
+		t.derived = t.thresholdArg < 0
+		t.setThreshold(threshold)
+	}
+
+	t.limit = t.memoryLimit()
+
+	if cfg.onTuneSet {
+		t.onTune.Store(onTuneFunc(cfg.onTune))
+	}
+
+	if cfg.strategy != nil {
+		t.strategy.Store(strategyHolder{cfg.strategy})
+	}
+
+	if cfg.runtimeAccountant != nil {
+		var v uint32
+		if *cfg.runtimeAccountant {
+			v = 1
+		}
+		atomic.StoreUint32(&t.runtimeAccountant, v)
+	}
+
+	if cfg.pressure != nil {
+		var m *pressureMonitor
+		if cfg.pressure.some > 0 || cfg.pressure.full > 0 {
+			m = newPressureMonitor(cfg.pressure.some, cfg.pressure.full)
+		}
+		t.pressure.Store(m)
+	}
+
+	if cfg.traceSize != nil {
+		t.statsMu.Lock()
+		t.trace.resize(*cfg.traceSize)
+		t.statsMu.Unlock()
+	}
+
+	if cfg.emergencySet {
+		var m *emergencyMonitor
+		if cfg.emergency != nil {
+			m = newEmergencyMonitor(*cfg.emergency)
+		}
+		t.emergency.Store(m)
+		t.clearPressureLevel()
+	}
+
+	if cfg.onLimitChangeSet {
+		t.onLimitChange = cfg.onLimitChange
+	}
+
+	if cfg.limitRefresh != nil {
+		t.refresh = *cfg.limitRefresh
+		if t.finalizer != nil {
+			t.startRefresh()
+		}
+	}
+
+	return nil
+}
+
+// resolveThreshold resolves a requested threshold to bytes. See [Enable].
+func (t *Tuner) resolveThreshold(threshold int64) (uint64, error) {
+	if threshold == 0 {
+		return 0, nil
+	}
+
+	if threshold < 0 {
+		if override, ok := t.getMemLimitOverride(); ok {
+			return override, nil
+		}
+
+		limit := t.MemLimitPercent(-1)
+		if limit == 0 {
+			return 0, fmt.Errorf("unable to resolve memory limit for threshold")
+		}
+
+		return limit, nil
+	}
+
+	return uint64(threshold), nil
 }
 
-func validateOptions(cfg *options) error {
-	min := GetMinGCPercent()
-	max := GetMaxGCPercent()
+func (t *Tuner) validateOptions(cfg *options) error {
+	min := t.MinGCPercent()
+	max := t.MaxGCPercent()
 
 	if cfg.minGCPercent != nil {
 		min = *cfg.minGCPercent
@@ -235,6 +462,38 @@ func validateOptions(cfg *options) error {
 		max = *cfg.maxGCPercent
 	}
 
+	if cfg.strategySet {
+		if err := validateStrategy(cfg.strategy); err != nil {
+			return err
+		}
+	}
+
+	if p := cfg.pressure; p != nil && (p.some < 0 || p.some > 100 || p.full < 0 || p.full > 100) {
+		return fmt.Errorf("invalid memory pressure thresholds: some=%g full=%g", p.some, p.full)
+	}
+
+	if cfg.emergency != nil {
+		if err := cfg.emergency.validate(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.traceSize != nil && *cfg.traceSize < 0 {
+		return fmt.Errorf("invalid trace size: %d", *cfg.traceSize)
+	}
+
+	if cfg.limitRefresh != nil && *cfg.limitRefresh < 0 {
+		return fmt.Errorf("invalid limit refresh interval: %s", *cfg.limitRefresh)
+	}
+
+	if cfg.sourceSet && cfg.source == nil {
+		return fmt.Errorf("invalid memory source: nil")
+	}
+
+	if cfg.runtimeSet && cfg.runtime == nil {
+		return fmt.Errorf("invalid runtime: nil")
+	}
+
 	return validateGCPercentRange(min, max)
 }
 
@@ -254,29 +513,93 @@ func validateGCPercentRange(min, max uint32) error {
 	return nil
 }
 
-// only allow one gc tuner in one process
-var globalTuner *tuner = nil
+// stop stops tuning and restores the runtime settings. It must be called
+// with t.mu held.
+func (t *Tuner) stop() {
+	if t.finalizer != nil {
+		t.finalizer.stop()
+		t.idle = t.finalizer
+		t.finalizer = nil
+	}
 
-/*
-	Heap
+	t.stopRefresh()
+	t.clearPressureLevel()
+	t.restoreRuntime()
+}
 
-________________  => limit: host/cgroup memory hard limit
-|               |
-|---------------| => threshold: increase GCPercent when gc_trigger < threshold
-|               |
-|---------------| => gc_trigger: heap_live + heap_live * GCPercent / 100
-|               |
-|---------------|
-|   heap_live   |
-|_______________|
+// startFinalizer starts tuning after every GC cycle, resuming the chain of
+// a previous start if it is still pending, so that repeated start and stop
+// cycles do not pile up finalizer chains. It must be called with t.mu held.
+func (t *Tuner) startFinalizer() {
+	if t.idle != nil && t.idle.resume() {
+		t.finalizer = t.idle
+	} else {
+		t.finalizer = newFinalizer(t.tuning)
+	}
 
-Go runtime only trigger GC when hit gc_trigger which affected by GCPercent and heap_live.
-So we can change GCPercent dynamically to tuning GC performance.
-*/
-type tuner struct {
-	finalizer *finalizer
-	gcPercent uint32
-	threshold uint64 // high water level, in bytes
+	t.idle = nil
+}
+
+func (t *Tuner) setThreshold(threshold uint64) {
+	atomic.StoreUint64(&t.threshold, threshold)
+}
+
+func (t *Tuner) getThreshold() uint64 {
+	return atomic.LoadUint64(&t.threshold)
+}
+
+func (t *Tuner) setGCPercent(percent uint32) uint32 {
+	atomic.StoreUint32(&t.gcPercent, percent)
+
+	return uint32(t.getRuntime().SetGCPercent(int(percent)))
+}
+
+func (t *Tuner) getGCPercent() uint32 {
+	return atomic.LoadUint32(&t.gcPercent)
+}
+
+func (t *Tuner) getSource() MemorySource {
+	return t.source.Load().(sourceHolder).MemorySource
+}
+
+// cpuTime returns the CPU time reported by the [MemorySource], if it is a
+// [CPUSource].
+func (t *Tuner) cpuTime() (gc, total float64) {
+	if src, ok := t.getSource().(CPUSource); ok {
+		return src.CPUTime()
+	}
+
+	return 0, 0
+}
+
+func (t *Tuner) getRuntime() Runtime {
+	return t.runtime.Load().(runtimeHolder).Runtime
+}
+
+func (t *Tuner) setOnTune(fn func(Decision)) {
+	t.onTune.Store(onTuneFunc(fn))
+}
+
+func (t *Tuner) setStrategy(s Strategy) {
+	t.strategy.Store(strategyHolder{s})
+}
+
+func (t *Tuner) getStrategy() Strategy {
+	if h, ok := t.strategy.Load().(strategyHolder); ok {
+		return h.Strategy
+	}
+
+	return LinearStrategy{}
+}
+
+func (t *Tuner) setPressure(m *pressureMonitor) {
+	t.pressure.Store(m)
+}
+
+// tuning is the finalizer callback. Go runtime ensure that it will be
+// called serially.
+func (t *Tuner) tuning() {
+	t.tune()
 }
 
 // threshold = inuse + inuse * (gcPercent / 100)
@@ -284,61 +607,141 @@ type tuner struct {
 // if threshold < inuse*2, so gcPercent < 100, and GC positively to avoid OOM
 // if threshold > inuse*2, so gcPercent > 100, and GC negatively to reduce GC times
 func calcGCPercent(inuse, threshold uint64) uint32 {
+	percent, _, _ := calcGCPercentBounded(inuse, threshold, GetMinGCPercent(), GetMaxGCPercent())
+
+	return percent
+}
+
+// calcGCPercentBounded is like calcGCPercent with explicit bounds, and also
+// reports whether the result was clamped to minPercent or maxPercent.
+func calcGCPercentBounded(inuse, threshold uint64, minPercent, maxPercent uint32) (uint32, bool, bool) {
 	// invalid params
 	if inuse == 0 || threshold == 0 {
-		return getDefaultGCPercent()
+		return getDefaultGCPercent(), false, false
 	}
 
-	minPercent := GetMinGCPercent()
-	maxPercent := GetMaxGCPercent()
+	// inuse heap at or above threshold yields 0, clamped to min percent
+	return clampGCPercent(linearGCPercent(inuse, threshold), minPercent, maxPercent)
+}
 
-	// inuse heap larger than threshold, use min percent
-	if threshold <= inuse {
-		return minPercent
+// apply sets the GC percent for inuse and threshold, records the decision,
+// and reports it to the callback set with [WithOnTune].
+func (t *Tuner) apply(inuse, goal, threshold, limit, nonHeap uint64) Decision {
+	now := time.Now()
+	minPercent := t.MinGCPercent()
+	maxPercent := t.MaxGCPercent()
+	prev := t.getGCPercent()
+
+	var (
+		percent                = getDefaultGCPercent()
+		clampedMin, clampedMax bool
+	)
+
+	// invalid params keep the default, as in calcGCPercent
+	if inuse != 0 && threshold != 0 {
+		gcCPU, totalCPU := t.cpuTime()
+		raw := t.getStrategy().GCPercent(Input{
+			Time:            now,
+			HeapInuse:       inuse,
+			HeapGoal:        goal,
+			Threshold:       threshold,
+			GCPercent:       prev,
+			MinGCPercent:    minPercent,
+			MaxGCPercent:    maxPercent,
+			GCCPUSeconds:    gcCPU,
+			TotalCPUSeconds: totalCPU,
+		})
+		percent, clampedMin, clampedMax = clampGCPercent(uint64(raw), minPercent, maxPercent)
 	}
 
-	diff := threshold - inuse
-	hi, lo := bits.Mul64(diff, 100)
-	q, _ := bits.Div64(hi, lo, inuse)
+	var p pressure
+	if m, _ := t.pressure.Load().(*pressureMonitor); m != nil {
+		p = m.sample(now)
+		if lowered := lowerGCPercent(percent, minPercent, m.ratio(p)); lowered != percent {
+			percent = lowered
+			clampedMin = percent == minPercent
+			clampedMax = false
+		}
+	}
 
-	gcPercent := uint32(q)
-	if gcPercent < minPercent {
-		return minPercent
-	} else if gcPercent > maxPercent {
-		return maxPercent
+	level, prevLevel := PressureNone, PressureNone
+	if m, _ := t.emergency.Load().(*emergencyMonitor); m != nil {
+		level, prevLevel = m.observe(inuse, threshold)
 	}
 
-	return gcPercent
-}
+	d := Decision{
+		Time:          now,
+		HeapInuse:     inuse,
+		HeapGoal:      goal,
+		Threshold:     threshold,
+		NonHeap:       nonHeap,
+		MemoryLimit:   limit,
+		PrevGCPercent: prev,
+		GCPercent:     percent,
+		MinGCPercent:  minPercent,
+		MaxGCPercent:  maxPercent,
+		ClampedToMin:  clampedMin,
+		ClampedToMax:  clampedMax,
+		PressureSome:  p.some,
+		PressureFull:  p.full,
+		PressureLevel: level,
+	}
 
-func newTuner(threshold uint64) *tuner {
-	t := &tuner{
-		gcPercent: getDefaultGCPercent(),
-		threshold: threshold,
+	t.setGCPercent(percent)
+
+	t.statsMu.Lock()
+	t.last = d
+	t.tunings++
+	if clampedMin {
+		t.minClamps++
+	}
+	if clampedMax {
+		t.maxClamps++
 	}
-	t.finalizer = newFinalizer(t.tuning) // start tuning
+	if level == PressureEmergency && prevLevel != PressureEmergency {
+		t.emergencies++
+	}
+	t.trace.add(d)
+	t.statsMu.Unlock()
 
-	return t
-}
+	if fn, _ := t.onTune.Load().(onTuneFunc); fn != nil {
+		fn(d)
+	}
 
-func (t *tuner) stop() {
-	t.finalizer.stop()
-}
+	t.setPressureLevel(level, prevLevel)
 
-func (t *tuner) setThreshold(threshold uint64) {
-	atomic.StoreUint64(&t.threshold, threshold)
+	return d
 }
 
-func (t *tuner) getThreshold() uint64 {
-	return atomic.LoadUint64(&t.threshold)
+// fillStats copies the recorded tuning state into s.
+func (t *Tuner) fillStats(s *Snapshot) {
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	s.MemoryLimit = t.last.MemoryLimit
+	s.HeapInuse = t.last.HeapInuse
+	s.HeapGoal = t.last.HeapGoal
+	s.NonHeap = t.last.NonHeap
+	s.Tunings = t.tunings
+	s.MinClamps = t.minClamps
+	s.MaxClamps = t.maxClamps
+	s.LastTuning = t.last.Time
+	s.PressureLevel = t.last.PressureLevel
+	s.Emergencies = t.emergencies
 }
 
-func (t *tuner) setGCPercent(percent uint32) uint32 {
-	atomic.StoreUint32(&t.gcPercent, percent)
+func (t *Tuner) resetStats() {
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
 
-	return uint32(debug.SetGCPercent(int(percent)))
-}
+	t.last = Decision{}
+	t.tunings = 0
+	t.minClamps = 0
+	t.maxClamps = 0
+	t.emergencies = 0
+	t.trace.reset()
 
-func (t *tuner) getGCPercent() uint32 {
-	return atomic.LoadUint32(&t.gcPercent)
+	if m, _ := t.emergency.Load().(*emergencyMonitor); m != nil {
+		m.reset()
+	}
 }
@@ -22,9 +22,9 @@ func TestTuner(t *testing.T) {
 		t.Fatalf("expected default gc percent %d, got %d", defaultGCPercent, currentGCPercent)
 	}
 
-	// wait for tuner set gcPercent to maxGCPercent
+	// wait for tuner set gcPercent to defaultMaxGCPercent
 	t.Logf("old gc percent before gc: %d", tn.getGCPercent())
-	for tn.getGCPercent() != maxGCPercent {
+	for tn.getGCPercent() != defaultMaxGCPercent {
 		runtime.GC()
 		t.Logf("new gc percent after gc: %d", tn.getGCPercent())
 	}
@@ -34,7 +34,7 @@ func TestTuner(t *testing.T) {
 
 	// wait for tuner set gcPercent to ~= 300
 	t.Logf("old gc percent before gc: %d", tn.getGCPercent())
-	for tn.getGCPercent() == maxGCPercent {
+	for tn.getGCPercent() == defaultMaxGCPercent {
 		runtime.GC()
 		t.Logf("new gc percent after gc: %d", tn.getGCPercent())
 	}
@@ -70,15 +70,15 @@ func TestTuner(t *testing.T) {
 	// 3/4 threshold
 	testHeap = make([]byte, threshold/4*3)
 
-	// wait for tuner set gcPercent to minGCPercent
+	// wait for tuner set gcPercent to defaultMinGCPercent
 	t.Logf("old gc percent before gc: %d", tn.getGCPercent())
-	for tn.getGCPercent() != minGCPercent {
+	for tn.getGCPercent() != defaultMinGCPercent {
 		runtime.GC()
 		t.Logf("new gc percent after gc: %d", tn.getGCPercent())
 	}
 
-	if tn.getGCPercent() != minGCPercent {
-		t.Fatalf("expected min gc percent %d, got %d", minGCPercent, tn.getGCPercent())
+	if tn.getGCPercent() != defaultMinGCPercent {
+		t.Fatalf("expected min gc percent %d, got %d", defaultMinGCPercent, tn.getGCPercent())
 	}
 
 	// out of threshold
@@ -88,17 +88,17 @@ func TestTuner(t *testing.T) {
 
 	for i := 0; i < 8; i++ {
 		runtime.GC()
-		if tn.getGCPercent() != minGCPercent {
-			t.Fatalf("expected min gc percent %d, got %d", minGCPercent, tn.getGCPercent())
+		if tn.getGCPercent() != defaultMinGCPercent {
+			t.Fatalf("expected min gc percent %d, got %d", defaultMinGCPercent, tn.getGCPercent())
 		}
 	}
 
 	// no heap
 	testHeap = nil
 
-	// wait for tuner set gcPercent to maxGCPercent
+	// wait for tuner set gcPercent to defaultMaxGCPercent
 	t.Logf("old gc percent before gc: %d", tn.getGCPercent())
-	for tn.getGCPercent() != maxGCPercent {
+	for tn.getGCPercent() != defaultMaxGCPercent {
 		runtime.GC()
 		t.Logf("new gc percent after gc: %d", tn.getGCPercent())
 	}
@@ -120,16 +120,16 @@ func TestCalcGCPercent(t *testing.T) {
 		t.Fatalf("expected default gc percent %d for (1,0)", defaultGCPercent)
 	}
 
-	if calcGCPercent(1, 3*gb) != maxGCPercent {
-		t.Fatalf("expected max gc percent %d for (1,3gb)", maxGCPercent)
+	if calcGCPercent(1, 3*gb) != defaultMaxGCPercent {
+		t.Fatalf("expected max gc percent %d for (1,3gb)", defaultMaxGCPercent)
 	}
 
-	if calcGCPercent(gb/10, 4*gb) != maxGCPercent {
-		t.Fatalf("expected max gc percent %d for (gb/10,4gb)", maxGCPercent)
+	if calcGCPercent(gb/10, 4*gb) != defaultMaxGCPercent {
+		t.Fatalf("expected max gc percent %d for (gb/10,4gb)", defaultMaxGCPercent)
 	}
 
-	if calcGCPercent(gb/2, 4*gb) != maxGCPercent {
-		t.Fatalf("expected max gc percent %d for (gb/2,4gb)", maxGCPercent)
+	if calcGCPercent(gb/2, 4*gb) != defaultMaxGCPercent {
+		t.Fatalf("expected max gc percent %d for (gb/2,4gb)", defaultMaxGCPercent)
 	}
 
 	if calcGCPercent(1*gb, 4*gb) != uint32(300) {
@@ -144,16 +144,16 @@ func TestCalcGCPercent(t *testing.T) {
 		t.Fatalf("expected gc percent 100 for (2gb,4gb)")
 	}
 
-	if calcGCPercent(3*gb, 4*gb) != minGCPercent {
-		t.Fatalf("expected min gc percent %d for (3gb,4gb)", minGCPercent)
+	if calcGCPercent(3*gb, 4*gb) != defaultMinGCPercent {
+		t.Fatalf("expected min gc percent %d for (3gb,4gb)", defaultMinGCPercent)
 	}
 
-	if calcGCPercent(4*gb, 4*gb) != minGCPercent {
-		t.Fatalf("expected min gc percent %d for (4gb,4gb)", minGCPercent)
+	if calcGCPercent(4*gb, 4*gb) != defaultMinGCPercent {
+		t.Fatalf("expected min gc percent %d for (4gb,4gb)", defaultMinGCPercent)
 	}
 
-	if calcGCPercent(5*gb, 4*gb) != minGCPercent {
-		t.Fatalf("expected min gc percent %d for (5gb,4gb)", minGCPercent)
+	if calcGCPercent(5*gb, 4*gb) != defaultMinGCPercent {
+		t.Fatalf("expected min gc percent %d for (5gb,4gb)", defaultMinGCPercent)
 	}
 }
 
@@ -165,7 +165,7 @@ func TestTunerSetGetThresholdAndGCPercent(t *testing.T) {
 	defer debug.SetGCPercent(prev)
 
 	tn := newTuner(1024)
-	defer tn.stop()
+	defer tn.Stop()
 
 	if got := tn.getThreshold(); got != 1024 {
 		t.Fatalf("expected threshold 1024, got %d", got)
@@ -193,10 +193,6 @@ func TestGetGCPercentWhenDisabled(t *testing.T) {
 	if err := Enable(0); err != nil {
 		t.Fatalf("unexpected error disabling tuner: %v", err)
 	}
-	if globalTuner != nil {
-		globalTuner.stop()
-		globalTuner = nil
-	}
 
 	defaultGCPercent = 123
 	defaultGCOnce = sync.Once{}
@@ -210,13 +206,13 @@ func TestGetGCPercentWhenEnabled(t *testing.T) {
 	cleanup := saveAndResetState(t)
 	defer cleanup()
 
-	tn := newTuner(4096)
-	defer tn.stop()
+	if err := Enable(4096); err != nil {
+		t.Fatalf("unexpected error enabling tuner: %v", err)
+	}
 
-	prev := tn.setGCPercent(222)
+	prev := std.setGCPercent(222)
 	defer debug.SetGCPercent(int(prev))
 
-	globalTuner = tn
 	if got := GetGCPercent(); got != 222 {
 		t.Fatalf("expected gc percent 222 when enabled, got %d", got)
 	}
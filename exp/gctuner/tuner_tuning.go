@@ -4,19 +4,20 @@
 
 package gctuner
 
-// tuning check the memory inuse and tune GC percent dynamically.
-// Go runtime ensure that it will be called serially.
-func (t *tuner) tuning() {
-	inuse := readMemoryInuse()
+// tune checks the memory inuse and tunes GC percent dynamically.
+func (t *Tuner) tune() Decision {
 	threshold := t.getThreshold()
 
 	// stop gc tuning
 	if threshold <= 0 {
-		return
+		return Decision{}
 	}
 
-	setMemoryLimit(threshold)
+	inuse, goal := t.getSource().Heap()
+	nonHeap, external := t.nonHeap()
+	limit := subtractFloor(t.effectiveMemoryLimit(threshold), external)
+	t.applyMemoryLimit(limit)
 
 	// keep adjusting GOGC to cooperate with memory limit
-	t.setGCPercent(calcGCPercent(inuse, threshold))
+	return t.apply(inuse, goal, subtractFloor(threshold, nonHeap), limit, nonHeap)
 }
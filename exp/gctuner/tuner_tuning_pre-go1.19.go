@@ -4,16 +4,17 @@
 
 package gctuner
 
-// tuning check the memory inuse and tune GC percent dynamically.
-// Go runtime ensure that it will be called serially.
-func (t *tuner) tuning() {
-	inuse := readMemoryInuse()
+// tune checks the memory inuse and tunes GC percent dynamically.
+func (t *Tuner) tune() Decision {
 	threshold := t.getThreshold()
 
 	// stop gc tuning
 	if threshold <= 0 {
-		return
+		return Decision{}
 	}
 
-	t.setGCPercent(calcGCPercent(inuse, threshold))
+	inuse, goal := t.getSource().Heap()
+	nonHeap, _ := t.nonHeap()
+
+	return t.apply(inuse, goal, subtractFloor(threshold, nonHeap), 0, nonHeap)
 }
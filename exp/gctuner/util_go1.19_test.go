@@ -6,60 +6,10 @@ package gctuner
 
 import (
 	"os"
+	"sync/atomic"
 	"testing"
 )
 
-func TestParseByteCount(t *testing.T) {
-	cases := []struct {
-		in     string
-		want   int64
-		wantOK bool
-	}{
-		{"1", 1, true},
-		{"1B", 1, true},
-		{"1KiB", 1024, true},
-		{"2MiB", 2 * 1024 * 1024, true},
-		{"1GiB", 1024 * 1024 * 1024, true},
-		{"1TiB", 1024 * 1024 * 1024 * 1024, true},
-		{"", 0, false},
-		{"K", 0, false},
-		{"1KB", 0, false},
-		{"1Ki", 0, false},
-		{"1XiB", 0, false},
-		{"-1", 0, false},
-		{"999999999999999999999999", 0, false},
-	}
-
-	for _, tc := range cases {
-		got, ok := parseByteCount(tc.in)
-		if ok != tc.wantOK || got != tc.want {
-			t.Fatalf("parseByteCount(%q) = (%d,%v), want (%d,%v)", tc.in, got, ok, tc.want, tc.wantOK)
-		}
-	}
-}
-
-func TestAtoi64(t *testing.T) {
-	cases := []struct {
-		in     string
-		want   int64
-		wantOK bool
-	}{
-		{"0", 0, true},
-		{"123", 123, true},
-		{"-1", -1, true},
-		{"", 0, false},
-		{"1a", 0, false},
-		{"999999999999999999999999", 0, false},
-	}
-
-	for _, tc := range cases {
-		got, ok := atoi64(tc.in)
-		if ok != tc.wantOK || got != tc.want {
-			t.Fatalf("atoi64(%q) = (%d,%v), want (%d,%v)", tc.in, got, ok, tc.want, tc.wantOK)
-		}
-	}
-}
-
 func TestToInt64(t *testing.T) {
 	if got := toInt64(1); got != 1 {
 		t.Fatalf("expected toInt64(1) = 1, got %d", got)
@@ -85,3 +35,51 @@ func TestReadGOMEMLIMIT(t *testing.T) {
 		t.Fatalf("expected readGOMEMLIMIT 128MiB, got %d", got)
 	}
 }
+
+func TestDetectLimitGOMEMLIMIT(t *testing.T) {
+	t.Setenv(EnvMemoryLimit, "")
+
+	// GOMEMLIMIT only wins when it is the lowest limit.
+	t.Setenv("GOMEMLIMIT", "1MiB")
+	if d := DetectLimit(); d.Limit != 1<<20 || d.Source != LimitSourceGOMEMLIMIT {
+		t.Fatalf("expected GOMEMLIMIT to win, got %+v", d)
+	}
+
+	t.Setenv("GOMEMLIMIT", "8EiB")
+	if d := DetectLimit(); d.Source == LimitSourceGOMEMLIMIT && len(d.Sources) > 1 {
+		t.Fatalf("expected a lower limit to win over GOMEMLIMIT, got %+v", d)
+	}
+}
+
+func TestTuneSubtractsExternalAccountantsFromMemoryLimit(t *testing.T) {
+	t.Setenv("GOMEMLIMIT", "")
+
+	rt := &fakeRuntime{}
+	tn, err := New(
+		WithMemorySource(&fakeMemory{inuse: 1 << 30}),
+		WithRuntime(rt),
+		WithThreshold(5<<30),
+		WithRuntimeAccountant(true),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	if err := tn.RegisterAccountant("cgo", func() uint64 { return 1 << 30 }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := tn.Tune()
+	if d.MemoryLimit != 4<<30 {
+		t.Fatalf("expected memory limit %d, got %d", uint64(4<<30), d.MemoryLimit)
+	}
+
+	if got := atomic.LoadInt64(&rt.memoryLimit); got != 4<<30 {
+		t.Fatalf("expected runtime memory limit %d, got %d", int64(4<<30), got)
+	}
+
+	if d.Threshold >= 4<<30 {
+		t.Fatalf("expected runtime non-heap memory to lower the threshold below the limit, got %d", d.Threshold)
+	}
+}
@@ -4,10 +4,24 @@
 
 package gctuner
 
+import "math"
+
 func readGOMEMLIMIT() int64 {
 	return 0
 }
 
-func setMemoryLimit(limit uint64) uint64 {
+func (t *Tuner) effectiveMemoryLimit(limit uint64) uint64 {
+	return 0
+}
+
+func (t *Tuner) applyMemoryLimit(limit uint64) uint64 {
 	return 0
 }
+
+func readMemoryLimit(rt Runtime) (int64, bool) {
+	return 0, false
+}
+
+func setRuntimeMemoryLimit(limit int64) int64 {
+	return math.MaxInt64
+}
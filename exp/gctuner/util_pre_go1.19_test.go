@@ -20,7 +20,15 @@ func TestReadGOMEMLIMITPreGo119(t *testing.T) {
 }
 
 func TestSetMemoryLimitPreGo119(t *testing.T) {
-	if got := setMemoryLimit(12345); got != 0 {
-		t.Fatalf("expected setMemoryLimit to return 0 on pre-go1.19, got %d", got)
+	rt := &fakeRuntime{}
+	tn, err := New(WithMemorySource(&fakeMemory{limit: 1 << 30}), WithRuntime(rt))
+	if err != nil {
+		t.Fatalf("unexpected error creating tuner: %v", err)
+	}
+	defer tn.Close()
+
+	tn.SetMemLimitPercent(50)
+	if got := tn.applyMemoryLimit(12345); got != 0 || rt.memoryLimit != 0 {
+		t.Fatalf("expected no memory limit on pre-go1.19, got %d (runtime %d)", got, rt.memoryLimit)
 	}
 }
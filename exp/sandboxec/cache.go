@@ -0,0 +1,162 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.dw1.io/x/exp/sandboxec/access"
+	"go.dw1.io/x/exp/sandboxec/internal/depcache"
+)
+
+// cacheConfig controls the dependency cache used by [WithUnsafeHostRuntime].
+//
+// It is scoped to the Sandboxec: it applies while that instance discovers its
+// host runtime, and other instances keep their own.
+type cacheConfig struct {
+	dir         string
+	disabled    bool
+	hashContent bool
+}
+
+// WithDependencyCache stores the runtime dependency cache under dir instead
+// of the user cache directory.
+//
+// The cache is kept in memory only if dir lies inside a path the policy
+// makes writable, so sandboxed commands cannot tamper with entries that
+// later widen host runtime rules. Once a policy has made dir writable, other
+// instances in the process also stop reading it from disk.
+func WithDependencyCache(dir string) Option {
+	return func(cfg *config) error {
+		if dir == "" {
+			return fmt.Errorf("%w: DependencyCache requires a directory", ErrInvalidOption)
+		}
+
+		cfg.cache.dir = filepath.Clean(dir)
+		cfg.cache.disabled = false
+
+		return nil
+	}
+}
+
+// WithoutDependencyCache disables the runtime dependency cache.
+//
+// Every dependency lookup runs discovery again, and nothing is read from or
+// written to disk. Use it on read-only images or for reproducible CI.
+func WithoutDependencyCache() Option {
+	return func(cfg *config) error {
+		cfg.cache.disabled = true
+
+		return nil
+	}
+}
+
+// WithDependencyCacheContentHash keys runtime dependency cache entries by a
+// SHA-256 of each file's contents instead of its metadata.
+//
+// This is slower but catches files rewritten in place with preserved
+// timestamps.
+func WithDependencyCacheContentHash() Option {
+	return func(cfg *config) error {
+		cfg.cache.hashContent = true
+
+		return nil
+	}
+}
+
+// ClearDependencyCache drops cached runtime dependency data from memory and
+// removes the cache files from the default cache directory. Directories set
+// with [WithDependencyCache] can be removed directly.
+func ClearDependencyCache() error {
+	return depcache.Clear()
+}
+
+// exposedCacheDirs records the cache directories that an enforced policy
+// made writable. Sandboxed commands may have rewritten their files, so no
+// instance in the process loads them from disk afterwards.
+var exposedCacheDirs sync.Map // resolved dir -> struct{}
+
+// scope applies c to the dependency cache for the host runtime discovery of
+// one instance, and returns the function that ends it. rules are the
+// filesystem rules about to be enforced.
+func (c cacheConfig) scope(rules []fsRule) (end func()) {
+	next := depcache.Config{
+		Dir:         c.dir,
+		Disabled:    c.disabled,
+		HashContent: c.hashContent,
+	}
+
+	dir := next.Dir
+	if dir == "" {
+		dir = depcache.DefaultDir()
+	}
+
+	if dir != "" {
+		resolved := resolvePath(dir)
+
+		for _, rule := range rules {
+			if rule.rights&^access.FS_READ_EXEC == 0 {
+				continue
+			}
+
+			if pathOverlaps(dir, rule.path) {
+				exposedCacheDirs.Store(resolved, struct{}{})
+				break
+			}
+		}
+
+		if _, ok := exposedCacheDirs.Load(resolved); ok {
+			next.MemoryOnly = true
+		}
+	}
+
+	return depcache.Scope(next)
+}
+
+// pathOverlaps reports whether a and b are the same path or one contains the
+// other, after resolving symlinks where possible.
+func pathOverlaps(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	a, b = resolvePath(a), resolvePath(b)
+
+	return isWithin(a, b) || isWithin(b, a)
+}
+
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	// Resolve the longest existing prefix, since the cache directory may not
+	// exist yet.
+	suffix := ""
+	for current := path; ; current = filepath.Dir(current) {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(resolved, suffix)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+
+		suffix = filepath.Join(filepath.Base(current), suffix)
+	}
+}
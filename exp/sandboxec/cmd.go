@@ -0,0 +1,115 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// exitErrorStderrLimit caps the stderr kept in [exec.ExitError] by Cmd Output,
+// like os/exec does.
+const exitErrorStderrLimit = 32 << 10
+
+// Cmd is a command created by a Sandboxec.
+//
+// It embeds [exec.Cmd], so its fields and methods work as in os/exec. Start,
+// Wait, Run, Output and CombinedOutput additionally report the command's start
+// and exit to [WithLogger], [OnCommandStart] and [OnCommandExit].
+type Cmd struct {
+	*exec.Cmd
+
+	ctx     context.Context
+	hooks   hookConfig
+	started time.Time
+	exited  bool
+}
+
+func newCmd(ctx context.Context, cmd *exec.Cmd, hooks hookConfig) *Cmd {
+	return &Cmd{Cmd: cmd, ctx: ctx, hooks: hooks}
+}
+
+// Start starts the command like [exec.Cmd.Start] and reports it to the start
+// hooks.
+func (c *Cmd) Start() error {
+	if err := c.Cmd.Start(); err != nil {
+		return err
+	}
+
+	c.started = time.Now()
+	c.hooks.commandStarted(c.ctx, c)
+
+	return nil
+}
+
+// Wait waits for the command like [exec.Cmd.Wait] and reports its exit to the
+// exit hooks, once.
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	if c.Process != nil && !c.exited {
+		c.exited = true
+		c.hooks.commandExited(c.ctx, c, time.Since(c.started), err)
+	}
+
+	return err
+}
+
+// Run starts the command and waits for it to complete, like [exec.Cmd.Run].
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+// Output runs the command and returns its standard output, like
+// [exec.Cmd.Output].
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+
+	var stderr *LimitedBuffer
+	if c.Stderr == nil {
+		stderr = NewLimitedBuffer(exitErrorStderrLimit)
+		c.Stderr = stderr
+	}
+
+	err := c.Run()
+	if stderr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitErr.Stderr = stderr.Bytes()
+		}
+	}
+
+	return stdout.Bytes(), err
+}
+
+// CombinedOutput runs the command and returns its combined standard output and
+// standard error, like [exec.Cmd.CombinedOutput].
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+
+	if c.Stderr != nil {
+		return nil, errors.New("exec: Stderr already set")
+	}
+
+	var b bytes.Buffer
+	c.Stdout = &b
+	c.Stderr = &b
+	err := c.Run()
+
+	return b.Bytes(), err
+}
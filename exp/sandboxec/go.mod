@@ -6,10 +6,10 @@ require (
 	github.com/go-webgpu/goffi v0.4.1
 	github.com/landlock-lsm/go-landlock v0.7.0
 	go.dw1.io/fastcache v0.2.0
+	golang.org/x/sys v0.40.0
 )
 
 require (
 	github.com/golang/snappy v1.0.0 // indirect
-	golang.org/x/sys v0.40.0 // indirect
 	kernel.org/pub/linux/libs/security/libcap/psx v1.2.77 // indirect
 )
@@ -0,0 +1,221 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"syscall"
+	"time"
+)
+
+// Report describes the policy applied by enforcement.
+type Report struct {
+	// Backend is "landlock" on Linux and "seatbelt" on Darwin.
+	Backend string
+
+	// ABI is the requested Landlock ABI and EffectiveABI the one enforced,
+	// which is lower when best-effort mode downgraded it. Both are zero on
+	// Darwin.
+	ABI          int
+	EffectiveABI int
+
+	// BestEffort reports whether WithBestEffort was set.
+	BestEffort bool
+
+	// Downgraded reports whether best-effort mode enforced less than was
+	// requested: a lower Landlock ABI on Linux, or a skipped Seatbelt policy
+	// on Darwin.
+	Downgraded bool
+
+	// FSRules and NetRules count the configured rules. FSRules includes the
+	// HostRuntimeRules discovered for WithUnsafeHostRuntime.
+	FSRules          int
+	NetRules         int
+	HostRuntimeRules int
+
+	// RuntimeDiscovery is the time spent discovering host runtime paths and
+	// their shared-library dependencies.
+	RuntimeDiscovery time.Duration
+
+	// Scopes and Network report the scoped IPC and network restrictions, as
+	// returned by [Sandboxec.Scopes] and [Sandboxec.NetworkMode].
+	Scopes  []ScopeStatus
+	Network NetworkMode
+
+	// PerCommand reports that each command enforces the policy itself instead
+	// of the current process, as with WithRootfs.
+	PerCommand bool
+
+	// Err is the enforcement error, if any.
+	Err error
+}
+
+type hookConfig struct {
+	logger    *slog.Logger
+	onEnforce []func(Report)
+	onStart   []func(*Cmd)
+	onExit    []func(*Cmd, error)
+}
+
+// WithLogger logs enforcement and command lifecycle events to l.
+//
+// Enforcement is logged once, at Info level, or at Warn level when best-effort
+// mode downgraded the policy, or at Error level when it failed. Command
+// creation is logged at Debug level, and each command's start and exit at
+// Info level.
+func WithLogger(l *slog.Logger) Option {
+	return func(cfg *config) error {
+		if l == nil {
+			return fmt.Errorf("%w: Logger requires a non-nil logger", ErrInvalidOption)
+		}
+
+		cfg.hooks.logger = l
+
+		return nil
+	}
+}
+
+// OnEnforce calls fn once with the enforcement report, after enforcement
+// succeeded or failed.
+//
+// fn runs on the goroutine that triggered enforcement, after it completed, so
+// it may create commands from the same Sandboxec.
+func OnEnforce(fn func(Report)) Option {
+	return func(cfg *config) error {
+		if fn == nil {
+			return fmt.Errorf("%w: OnEnforce requires a non-nil function", ErrInvalidOption)
+		}
+
+		cfg.hooks.onEnforce = append(cfg.hooks.onEnforce, fn)
+
+		return nil
+	}
+}
+
+// OnCommandStart calls fn after a command created by the Sandboxec has
+// started, whether by [Cmd.Start], [Cmd.Run] or [Sandboxec.Run].
+func OnCommandStart(fn func(*Cmd)) Option {
+	return func(cfg *config) error {
+		if fn == nil {
+			return fmt.Errorf("%w: OnCommandStart requires a non-nil function", ErrInvalidOption)
+		}
+
+		cfg.hooks.onStart = append(cfg.hooks.onStart, fn)
+
+		return nil
+	}
+}
+
+// OnCommandExit calls fn with the wait error after a command created by the
+// Sandboxec has exited and was waited for. It is not called for commands that
+// failed to start.
+func OnCommandExit(fn func(*Cmd, error)) Option {
+	return func(cfg *config) error {
+		if fn == nil {
+			return fmt.Errorf("%w: OnCommandExit requires a non-nil function", ErrInvalidOption)
+		}
+
+		cfg.hooks.onExit = append(cfg.hooks.onExit, fn)
+
+		return nil
+	}
+}
+
+func (h hookConfig) log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if h.logger == nil {
+		return
+	}
+
+	h.logger.Log(ctx, level, msg, args...)
+}
+
+func (h hookConfig) enforced(r Report) {
+	if h.logger != nil {
+		args := []any{
+			slog.String("backend", r.Backend),
+			slog.Int("abi", r.ABI),
+			slog.Int("effective_abi", r.EffectiveABI),
+			slog.Bool("best_effort", r.BestEffort),
+			slog.Int("fs_rules", r.FSRules),
+			slog.Int("net_rules", r.NetRules),
+			slog.Int("host_runtime_rules", r.HostRuntimeRules),
+			slog.Duration("runtime_discovery", r.RuntimeDiscovery),
+			slog.String("network", r.Network.String()),
+		}
+
+		for _, scope := range r.Scopes {
+			args = append(args, slog.Bool("scope_"+scope.Name, scope.Enforced))
+		}
+
+		if r.PerCommand {
+			args = append(args, slog.Bool("per_command", true))
+		}
+
+		switch {
+		case r.Err != nil:
+			h.log(context.Background(), slog.LevelError, "sandboxec: enforcement failed", append(args, slog.Any("error", r.Err))...)
+		case r.Downgraded:
+			h.log(context.Background(), slog.LevelWarn, "sandboxec: enforced with best-effort downgrade", args...)
+		default:
+			h.log(context.Background(), slog.LevelInfo, "sandboxec: enforced", args...)
+		}
+	}
+
+	for _, fn := range h.onEnforce {
+		fn(r)
+	}
+}
+
+func (h hookConfig) commandCreated(name string, args []string, err error) {
+	if err != nil {
+		h.log(context.Background(), slog.LevelDebug, "sandboxec: command created", slog.String("name", name), slog.Any("args", args), slog.Any("error", err))
+
+		return
+	}
+
+	h.log(context.Background(), slog.LevelDebug, "sandboxec: command created", slog.String("name", name), slog.Any("args", args))
+}
+
+func (h hookConfig) commandStarted(ctx context.Context, cmd *Cmd) {
+	h.log(ctx, slog.LevelInfo, "sandboxec: command started",
+		slog.String("path", cmd.Path),
+		slog.Any("args", cmd.Args),
+		slog.Int("pid", cmd.Process.Pid),
+	)
+
+	for _, fn := range h.onStart {
+		fn(cmd)
+	}
+}
+
+func (h hookConfig) commandExited(ctx context.Context, cmd *Cmd, wall time.Duration, err error) {
+	if h.logger != nil {
+		args := []any{
+			slog.String("path", cmd.Path),
+			slog.Int("pid", cmd.Process.Pid),
+			slog.Duration("wall_time", wall),
+		}
+
+		if state := cmd.ProcessState; state != nil {
+			args = append(args, slog.Int("exit_code", state.ExitCode()))
+
+			if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				args = append(args, slog.String("signal", status.Signal().String()))
+			}
+		}
+
+		if err != nil {
+			args = append(args, slog.Any("error", err))
+		}
+
+		h.log(ctx, slog.LevelInfo, "sandboxec: command exited", args...)
+	}
+
+	for _, fn := range h.onExit {
+		fn(cmd, err)
+	}
+}
@@ -0,0 +1,104 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"go.dw1.io/x/exp/sandboxec/access"
+)
+
+func TestHookOptionsRejectNil(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "WithLogger", opt: WithLogger(nil)},
+		{name: "OnEnforce", opt: OnEnforce(nil)},
+		{name: "OnCommandStart", opt: OnCommandStart(nil)},
+		{name: "OnCommandExit", opt: OnCommandExit(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			if err := tt.opt(&cfg); !errors.Is(err, ErrInvalidOption) {
+				t.Fatalf("expected ErrInvalidOption, got %v", err)
+			}
+		})
+	}
+}
+
+func TestHooksReportEnforcementFailure(t *testing.T) {
+	var logs bytes.Buffer
+	var reports []Report
+	started := false
+
+	sb := New(
+		WithFSRule("", access.FS_READ),
+		WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		OnEnforce(func(r Report) {
+			reports = append(reports, r)
+		}),
+		OnCommandStart(func(*Cmd) {
+			started = true
+		}),
+	)
+
+	_, err := sb.Run(context.Background(), RunSpec{Name: "/bin/true"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+
+	_ = sb.Enforce()
+
+	if len(reports) != 1 || !errors.Is(reports[0].Err, ErrInvalidOption) {
+		t.Fatalf("expected one failed report, got %+v", reports)
+	}
+
+	if started {
+		t.Fatalf("OnCommandStart must not run for a command that never started")
+	}
+
+	for _, want := range []string{"level=ERROR", "sandboxec: enforcement failed", "level=DEBUG", "sandboxec: command created"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestOnEnforceMayCreateCommands(t *testing.T) {
+	var sb *Sandboxec
+	var created *Cmd
+
+	sb = New(
+		WithFSRule("", access.FS_READ),
+		OnEnforce(func(Report) {
+			created = sb.Command("/bin/true")
+		}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = sb.Enforce()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("OnEnforce creating a command deadlocked")
+	}
+
+	if created == nil || !errors.Is(created.Err, ErrInvalidOption) {
+		t.Fatalf("expected a command carrying the enforcement error, got %+v", created)
+	}
+}
@@ -0,0 +1,371 @@
+package depcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.dw1.io/fastcache"
+)
+
+const maxEntries = 16_384
+
+// Config controls all dependency caches in the process.
+type Config struct {
+	// Dir is the base directory for cache files. Each cache stores its data
+	// in Dir/<name>/deps.cache. Empty means DefaultDir.
+	Dir string
+
+	// Disabled turns caching off entirely; every lookup misses.
+	Disabled bool
+
+	// MemoryOnly keeps entries in memory without loading or saving files.
+	MemoryOnly bool
+
+	// HashContent keys entries by a SHA-256 of the file contents instead of
+	// file metadata.
+	HashContent bool
+}
+
+type cache interface {
+	cacheName() string
+	reset()
+	flush() error
+}
+
+var (
+	mu     sync.Mutex
+	cfg    Config
+	caches []cache
+
+	// scopeMu serializes Scope calls.
+	scopeMu sync.Mutex
+)
+
+// DefaultDir returns the default base directory for cache files, or an empty
+// string if the user cache directory is unknown.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil || dir == "" {
+		return ""
+	}
+
+	return filepath.Join(dir, "go.dw1.io", "x", "exp", "sandboxec")
+}
+
+// Configure replaces the process-wide configuration.
+//
+// Pending entries are saved under the previous configuration first; caches
+// then reload lazily from the new location on next use.
+func Configure(c Config) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, registered := range caches {
+		_ = registered.flush()
+		registered.reset()
+	}
+
+	cfg = c
+}
+
+// Scope applies c until the returned function is called, which saves the
+// entries added under c and restores the previous configuration.
+//
+// Scopes let each caller use its own configuration for a batch of lookups
+// without changing it for the others. They do not overlap: Scope blocks
+// until the previous scope has ended.
+func Scope(c Config) (end func()) {
+	scopeMu.Lock()
+
+	previous := Current()
+	Configure(c)
+
+	return func() {
+		Configure(previous)
+		scopeMu.Unlock()
+	}
+}
+
+// Current returns the process-wide configuration.
+func Current() Config {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return cfg
+}
+
+// Dir returns the resolved base directory for cache files, or an empty
+// string when nothing is persisted.
+func Dir() string {
+	return Current().dir()
+}
+
+func (c Config) dir() string {
+	if c.Disabled || c.MemoryOnly {
+		return ""
+	}
+
+	if c.Dir != "" {
+		return c.Dir
+	}
+
+	return DefaultDir()
+}
+
+// Flush saves every cache with unsaved entries.
+func Flush() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var errs []error
+	for _, c := range caches {
+		if err := c.flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Clear drops all in-memory entries and removes the cache files of every
+// registered cache under the configured directory.
+func Clear() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, c := range caches {
+		c.reset()
+	}
+
+	dir := cfg.Dir
+	if dir == "" {
+		dir = DefaultDir()
+	}
+
+	if dir == "" {
+		return nil
+	}
+
+	var errs []error
+	for _, c := range caches {
+		file := filepath.Join(dir, c.cacheName(), "deps.cache")
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Cache maps files to dependency data of type V.
+type Cache[V any] struct {
+	name string
+
+	mu       sync.Mutex
+	entries  *fastcache.Cache[string, V]
+	file     string
+	writes   int
+	lastSave time.Time
+}
+
+// New registers and returns a cache stored under name.
+func New[V any](name string) *Cache[V] {
+	c := &Cache[V]{name: name}
+
+	mu.Lock()
+	caches = append(caches, c)
+	mu.Unlock()
+
+	return c
+}
+
+// Get returns the entry for file, if present and still valid.
+func (c *Cache[V]) Get(file string) (V, bool) {
+	var zero V
+
+	key, ok := Key(file)
+	if !ok {
+		return zero, false
+	}
+
+	entries := c.load()
+	if entries == nil {
+		return zero, false
+	}
+
+	return entries.Get(key)
+}
+
+// Set stores v for file and saves the cache periodically.
+func (c *Cache[V]) Set(file string, v V) {
+	key, ok := Key(file)
+	if !ok {
+		return
+	}
+
+	entries := c.load()
+	if entries == nil {
+		return
+	}
+
+	entries.Set(key, v)
+	c.maybeSave()
+}
+
+func (c *Cache[V]) cacheName() string {
+	return c.name
+}
+
+func (c *Cache[V]) load() *fastcache.Cache[string, V] {
+	current := Current()
+	if current.Disabled {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries != nil {
+		return c.entries
+	}
+
+	dir := current.dir()
+	if dir == "" {
+		c.entries = fastcache.New[string, V](maxEntries)
+		return c.entries
+	}
+
+	c.file = filepath.Join(dir, c.name, "deps.cache")
+	if err := os.MkdirAll(filepath.Dir(c.file), 0o755); err != nil {
+		c.entries = fastcache.New[string, V](maxEntries)
+		c.file = ""
+		return c.entries
+	}
+
+	c.entries = fastcache.LoadFromFileOrNew[string, V](c.file, maxEntries)
+
+	return c.entries
+}
+
+func (c *Cache[V]) maybeSave() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == "" || c.entries == nil {
+		return
+	}
+
+	c.writes++
+	if c.writes < 32 && time.Since(c.lastSave) < 2*time.Second {
+		return
+	}
+
+	if err := c.entries.SaveToFile(c.file); err == nil {
+		c.writes = 0
+		c.lastSave = time.Now()
+	}
+}
+
+func (c *Cache[V]) flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.file == "" || c.entries == nil || c.writes == 0 {
+		return nil
+	}
+
+	if err := c.entries.SaveToFile(c.file); err != nil {
+		return err
+	}
+
+	c.writes = 0
+	c.lastSave = time.Now()
+
+	return nil
+}
+
+func (c *Cache[V]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = nil
+	c.file = ""
+	c.writes = 0
+}
+
+// Key returns the cache key for file under the current configuration.
+//
+// It reports false for missing or non-regular files.
+func Key(file string) (string, bool) {
+	if file == "" {
+		return "", false
+	}
+
+	info, err := os.Stat(file)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(file) + 96)
+	builder.WriteString(file)
+	builder.WriteByte('|')
+	builder.WriteString(strconv.FormatInt(info.Size(), 10))
+
+	if Current().HashContent {
+		sum, err := hashFile(file)
+		if err != nil {
+			return "", false
+		}
+
+		builder.WriteString("|sha256:")
+		builder.WriteString(sum)
+
+		return builder.String(), true
+	}
+
+	builder.WriteByte('|')
+	builder.WriteString(strconv.FormatInt(info.ModTime().UnixNano(), 10))
+
+	if id, ok := statID(info); ok {
+		builder.WriteByte('|')
+		builder.WriteString(strconv.FormatUint(id.dev, 10))
+		builder.WriteByte('|')
+		builder.WriteString(strconv.FormatUint(id.ino, 10))
+		builder.WriteByte('|')
+		builder.WriteString(strconv.FormatInt(id.ctime, 10))
+	}
+
+	return builder.String(), true
+}
+
+type fileID struct {
+	dev   uint64
+	ino   uint64
+	ctime int64
+}
+
+func hashFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,196 @@
+package depcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func configureForTest(t *testing.T, c Config) {
+	t.Helper()
+
+	previous := Current()
+	Configure(c)
+	t.Cleanup(func() {
+		Configure(previous)
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %q: %v", path, err)
+	}
+}
+
+func TestKeyInvalidation(t *testing.T) {
+	configureForTest(t, Config{MemoryOnly: true})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "lib.so")
+	writeFile(t, file, "aaaa")
+
+	key1, ok := Key(file)
+	if !ok {
+		t.Fatalf("expected key for regular file")
+	}
+
+	// Replace the file with same-size contents and the original mtime; only
+	// the inode and ctime differ.
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replacement := filepath.Join(dir, "lib.so.new")
+	writeFile(t, replacement, "bbbb")
+	if err := os.Chtimes(replacement, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(replacement, file); err != nil {
+		t.Fatal(err)
+	}
+
+	key2, ok := Key(file)
+	if !ok {
+		t.Fatalf("expected key for replaced file")
+	}
+
+	if key1 == key2 {
+		t.Fatalf("expected key to change after file replacement, got %q", key1)
+	}
+
+	if _, ok := Key(dir); ok {
+		t.Fatalf("expected no key for a directory")
+	}
+
+	if _, ok := Key(filepath.Join(dir, "missing")); ok {
+		t.Fatalf("expected no key for a missing file")
+	}
+}
+
+func TestKeyContentHash(t *testing.T) {
+	configureForTest(t, Config{MemoryOnly: true, HashContent: true})
+
+	file := filepath.Join(t.TempDir(), "lib.so")
+	writeFile(t, file, "aaaa")
+
+	key1, _ := Key(file)
+
+	// Rewriting identical contents keeps the key in content-hash mode.
+	time.Sleep(10 * time.Millisecond)
+	writeFile(t, file, "aaaa")
+
+	key2, _ := Key(file)
+	if key1 != key2 {
+		t.Fatalf("expected identical contents to keep the key: %q != %q", key1, key2)
+	}
+
+	writeFile(t, file, "bbbb")
+
+	key3, _ := Key(file)
+	if key1 == key3 {
+		t.Fatalf("expected different contents to change the key")
+	}
+}
+
+func TestCachePersistAndClear(t *testing.T) {
+	dir := t.TempDir()
+	configureForTest(t, Config{Dir: dir})
+
+	cache := New[[]string]("test")
+	file := filepath.Join(t.TempDir(), "bin")
+	writeFile(t, file, "elf")
+
+	cache.Set(file, []string{"/lib/libc.so.6"})
+	if err := Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	cacheFile := filepath.Join(dir, "test", "deps.cache")
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Fatalf("expected cache file after Flush: %v", err)
+	}
+
+	// A fresh configuration reloads the persisted entry.
+	Configure(Config{Dir: dir})
+	if got, ok := cache.Get(file); !ok || len(got) != 1 || got[0] != "/lib/libc.so.6" {
+		t.Fatalf("unexpected reloaded entry: %v, %v", got, ok)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+
+	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+		t.Fatalf("expected cache file to be removed, got %v", err)
+	}
+
+	if _, ok := cache.Get(file); ok {
+		t.Fatalf("expected cleared cache to miss")
+	}
+}
+
+func TestCacheDisabledAndMemoryOnly(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(t.TempDir(), "bin")
+	writeFile(t, file, "elf")
+
+	cache := New[string]("modes")
+
+	configureForTest(t, Config{Dir: dir, Disabled: true})
+	cache.Set(file, "v")
+	if _, ok := cache.Get(file); ok {
+		t.Fatalf("expected disabled cache to miss")
+	}
+
+	Configure(Config{Dir: dir, MemoryOnly: true})
+	cache.Set(file, "v")
+	if got, ok := cache.Get(file); !ok || got != "v" {
+		t.Fatalf("expected memory-only cache hit, got %q, %v", got, ok)
+	}
+	if err := Flush(); err != nil {
+		t.Fatalf("Flush returned error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "modes")); !os.IsNotExist(err) {
+		t.Fatalf("expected memory-only cache to leave no files, got %v", err)
+	}
+
+	if Dir() != "" {
+		t.Fatalf("expected empty Dir in memory-only mode, got %q", Dir())
+	}
+}
+
+func TestScope(t *testing.T) {
+	configureForTest(t, Config{Dir: t.TempDir()})
+	base := Current()
+
+	end := Scope(Config{Disabled: true})
+	if got := Current(); !got.Disabled {
+		t.Fatalf("expected the scoped config, got %+v", got)
+	}
+
+	ended := make(chan struct{})
+	go func() {
+		defer close(ended)
+
+		// Scopes do not overlap.
+		Scope(Config{MemoryOnly: true})()
+	}()
+
+	select {
+	case <-ended:
+		t.Fatal("expected the second scope to wait for the first")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	end()
+	<-ended
+
+	if got := Current(); got != base {
+		t.Fatalf("expected %+v restored, got %+v", base, got)
+	}
+}
@@ -0,0 +1,8 @@
+// Package depcache persists dependency-discovery results for sandboxec.
+//
+// Entries are keyed by file path plus device, inode, size, modification and
+// change times, or by a content hash when enabled, so replaced or rewritten
+// files are never served stale results. Caches are process-wide and can be
+// relocated, kept in memory only, disabled, or cleared through Configure and
+// Clear. Scope applies a configuration to a single batch of lookups.
+package depcache
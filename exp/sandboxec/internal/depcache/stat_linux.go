@@ -0,0 +1,21 @@
+//go:build linux
+
+package depcache
+
+import (
+	"os"
+	"syscall"
+)
+
+func statID(info os.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+
+	return fileID{
+		dev:   uint64(st.Dev),
+		ino:   uint64(st.Ino),
+		ctime: st.Ctim.Nano(),
+	}, true
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd
+
+package depcache
+
+import "os"
+
+func statID(info os.FileInfo) (fileID, bool) {
+	_ = info
+
+	return fileID{}, false
+}
@@ -18,23 +18,13 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
 	"sync"
-	"time"
 
-	"go.dw1.io/fastcache"
+	"go.dw1.io/x/exp/sandboxec/internal/depcache"
 )
 
-var (
-	depCacheOnce sync.Once
-	depCache     *fastcache.Cache[string, depCacheEntry]
-	depCacheFile string
-
-	depCacheSaveMu     sync.Mutex
-	depCacheLastSaveAt time.Time
-	depCacheWrites     int
-)
+var depCache = depcache.New[depCacheEntry]("ldd")
 
 type depCacheEntry struct {
 	Interp  string
@@ -163,67 +153,6 @@ func runinterp(interp, file string) ([]string, error) {
 	return names, nil
 }
 
-func getDepCache() *fastcache.Cache[string, depCacheEntry] {
-	depCacheOnce.Do(func() {
-		cacheDir, err := os.UserCacheDir()
-		if err != nil || cacheDir == "" {
-			depCache = fastcache.New[string, depCacheEntry](16_384)
-			return
-		}
-
-		depCacheFile = filepath.Join(cacheDir, "go.dw1.io", "x", "exp", "sandboxec", "ldd", "deps.cache")
-		if err := os.MkdirAll(filepath.Dir(depCacheFile), 0o755); err != nil {
-			depCache = fastcache.New[string, depCacheEntry](16_384)
-			depCacheFile = ""
-			return
-		}
-
-		depCache = fastcache.LoadFromFileOrNew[string, depCacheEntry](depCacheFile, 16_384)
-	})
-
-	return depCache
-}
-
-func depCacheKey(file string) (string, bool) {
-	if file == "" {
-		return "", false
-	}
-
-	info, err := os.Stat(file)
-	if err != nil || !info.Mode().IsRegular() {
-		return "", false
-	}
-
-	var builder strings.Builder
-	builder.Grow(len(file) + 56)
-	builder.WriteString(file)
-	builder.WriteByte('|')
-	builder.WriteString(strconv.FormatInt(info.Size(), 10))
-	builder.WriteByte('|')
-	builder.WriteString(strconv.FormatInt(info.ModTime().UnixNano(), 10))
-
-	return builder.String(), true
-}
-
-func maybeSaveDepCache() {
-	if depCacheFile == "" || depCache == nil {
-		return
-	}
-
-	depCacheSaveMu.Lock()
-	defer depCacheSaveMu.Unlock()
-
-	depCacheWrites++
-	if depCacheWrites < 32 && time.Since(depCacheLastSaveAt) < 2*time.Second {
-		return
-	}
-
-	if err := depCache.SaveToFile(depCacheFile); err == nil {
-		depCacheWrites = 0
-		depCacheLastSaveAt = time.Now()
-	}
-}
-
 func GetInterp(file string) (string, error) {
 	r, err := os.Open(file)
 	if err != nil {
@@ -262,18 +191,13 @@ func GetInterp(file string) (string, error) {
 		if f.Type != elf.ET_DYN || f.Class == elf.ELFCLASSNONE {
 			return "", nil
 		}
-		bit64 := true
-		if f.Class != elf.ELFCLASS64 {
-			bit64 = false
-		}
 
 		// This is a shared library. Turns out you can run an
 		// interpreter with --list and this shared library as an
-		// argument. What interpreter do we use? Well, there's no way to
-		// know. You have to guess.  I'm not sure why they could not
-		// just put an interp section in .so's but maybe that would
-		// cause trouble somewhere else.
-		interp, err = LdSo(bit64)
+		// argument. What interpreter do we use? There's no .interp to
+		// tell, so derive the loader from the ELF header's machine and
+		// the libc it links against.
+		interp, err = LdSo("/", platformFromELF(f, r, file))
 		if err != nil {
 			return "fail", err
 		}
@@ -342,8 +266,6 @@ func List(names ...string) ([]string, error) {
 		return nil, nil
 	}
 
-	cache := getDepCache()
-
 	workerCount := min(min(max(runtime.GOMAXPROCS(0), 1), 8), len(names))
 
 	type listResult struct {
@@ -360,15 +282,9 @@ func List(names ...string) ([]string, error) {
 		go func() {
 			defer wg.Done()
 			for n := range jobs {
-				cacheKey := ""
-				if key, ok := depCacheKey(n); ok {
-					cacheKey = key
-					if cached, found := cache.Get(cacheKey); found {
-						if cached.Interp != "" {
-							results <- listResult{interp: cached.Interp, sonames: cached.SONames}
-							continue
-						}
-					}
+				if cached, found := depCache.Get(n); found && cached.Interp != "" {
+					results <- listResult{interp: cached.Interp, sonames: cached.SONames}
+					continue
 				}
 
 				interp, err := GetInterp(n)
@@ -382,10 +298,7 @@ func List(names ...string) ([]string, error) {
 					continue
 				}
 
-				if cacheKey != "" {
-					cache.Set(cacheKey, depCacheEntry{Interp: interp, SONames: sonames})
-					maybeSaveDepCache()
-				}
+				depCache.Set(n, depCacheEntry{Interp: interp, SONames: sonames})
 
 				results <- listResult{interp: interp, sonames: sonames}
 			}
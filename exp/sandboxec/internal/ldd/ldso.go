@@ -5,28 +5,51 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+//go:build freebsd || linux
+
 package ldd
 
 import (
+	"debug/elf"
 	"fmt"
+	"os"
 	"path/filepath"
 )
 
-// LdSo finds the loader binary.
-func LdSo(bit64 bool) (string, error) {
+// LdSo finds the loader binary for p below root.
+//
+// It looks for p.Loader in the platform's run paths and default library
+// directories first, then falls back to globbing for any ld.so of the same
+// word size. The returned path is absolute within root, that is, root is
+// not part of it.
+func LdSo(root string, p Platform) (string, error) {
+	if root == "" {
+		root = "/"
+	}
+
+	if p.Loader != "" {
+		dirs := append(append([]string(nil), p.RunPaths...), p.LibDirs()...)
+		for _, dir := range dirs {
+			candidate := filepath.Join(dir, p.Loader)
+			if _, err := os.Stat(filepath.Join(root, candidate)); err == nil {
+				return candidate, nil
+			}
+		}
+	}
+
 	bits := 32
-	if bit64 {
+	if p.Class == elf.ELFCLASS64 {
 		bits = 64
 	}
 	choices := []string{fmt.Sprintf("/lib%d/ld-*.so.*", bits), "/lib/ld-*.so.*"}
 	for _, d := range choices {
-		n, err := filepath.Glob(d)
+		n, err := filepath.Glob(filepath.Join(root, d))
 		if err != nil {
 			return "", err
 		}
 		if len(n) > 0 {
-			return n[0], nil
+			return filepath.Join("/", n[0][len(filepath.Clean(root)):]), nil
 		}
 	}
-	return "", fmt.Errorf("could not find ld.so in %v", choices)
+	return "", fmt.Errorf("could not find ld.so for %s in %v", p.Triplet, choices)
 }
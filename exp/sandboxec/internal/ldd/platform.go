@@ -0,0 +1,313 @@
+// Copyright 2026 Dwi Siswanto.
+// Licensed under the Apache License, Version 2.0.
+
+//go:build freebsd || linux
+
+package ldd
+
+import (
+	"debug/elf"
+	"io"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+)
+
+// Libc identifies the C library flavor a binary was linked against.
+type Libc int
+
+const (
+	// LibcUnknown means the flavor could not be determined.
+	LibcUnknown Libc = iota
+	// LibcGNU is glibc.
+	LibcGNU
+	// LibcMusl is musl libc.
+	LibcMusl
+)
+
+// String returns the libc name used in multiarch triplets.
+func (l Libc) String() string {
+	switch l {
+	case LibcGNU:
+		return "gnu"
+	case LibcMusl:
+		return "musl"
+	default:
+		return "unknown"
+	}
+}
+
+// Platform describes the architecture and C library of an ELF binary, and
+// the dynamic-linker layout derived from them.
+type Platform struct {
+	// Class and Machine come from the ELF header.
+	Class   elf.Class
+	Machine elf.Machine
+
+	// Libc is detected from the requested interpreter or, for shared
+	// objects, from the libc DT_NEEDED entry.
+	Libc Libc
+
+	// Triplet is the Debian-style multiarch triplet, for example
+	// "aarch64-linux-gnu". It is empty for unknown machines.
+	Triplet string
+
+	// Loader is the file name of the dynamic loader, for example
+	// "ld-linux-aarch64.so.1" or "ld-musl-x86_64.so.1".
+	Loader string
+
+	// RunPaths holds DT_RUNPATH and DT_RPATH entries with $ORIGIN expanded.
+	// Nix-style layouts rely on them instead of the default directories.
+	RunPaths []string
+}
+
+type archInfo struct {
+	class       elf.Class
+	machine     elf.Machine
+	gnuTriplet  string
+	gnuLoader   string
+	muslTriplet string
+	muslArch    string
+}
+
+// archTable maps ELF class and machine to multiarch names. Machines with
+// per-ABI variants (ARM float ABI, PowerPC endianness) are fixed up in
+// newPlatform.
+var archTable = []archInfo{
+	{elf.ELFCLASS64, elf.EM_X86_64, "x86_64-linux-gnu", "ld-linux-x86-64.so.2", "x86_64-linux-musl", "x86_64"},
+	{elf.ELFCLASS32, elf.EM_X86_64, "x86_64-linux-gnux32", "ld-linux-x32.so.2", "x86_64-linux-muslx32", "x32"},
+	{elf.ELFCLASS32, elf.EM_386, "i386-linux-gnu", "ld-linux.so.2", "i386-linux-musl", "i386"},
+	{elf.ELFCLASS64, elf.EM_AARCH64, "aarch64-linux-gnu", "ld-linux-aarch64.so.1", "aarch64-linux-musl", "aarch64"},
+	{elf.ELFCLASS32, elf.EM_ARM, "arm-linux-gnueabihf", "ld-linux-armhf.so.3", "arm-linux-musleabihf", "armhf"},
+	{elf.ELFCLASS64, elf.EM_RISCV, "riscv64-linux-gnu", "ld-linux-riscv64-lp64d.so.1", "riscv64-linux-musl", "riscv64"},
+	{elf.ELFCLASS64, elf.EM_PPC64, "powerpc64le-linux-gnu", "ld64.so.2", "powerpc64le-linux-musl", "powerpc64le"},
+	{elf.ELFCLASS64, elf.EM_S390, "s390x-linux-gnu", "ld64.so.1", "s390x-linux-musl", "s390x"},
+	{elf.ELFCLASS64, elf.EM_LOONGARCH, "loongarch64-linux-gnu", "ld-linux-loongarch-lp64d.so.1", "loongarch64-linux-musl", "loongarch64"},
+	{elf.ELFCLASS64, elf.EM_MIPS, "mips64el-linux-gnuabi64", "ld.so.1", "mips64el-linux-musl", "mips64el"},
+	{elf.ELFCLASS32, elf.EM_MIPS, "mipsel-linux-gnu", "ld.so.1", "mipsel-linux-musl", "mipsel"},
+}
+
+// efARMABIFloatHard is EF_ARM_ABI_FLOAT_HARD from the ARM ELF ABI.
+const efARMABIFloatHard = 0x400
+
+// DetectPlatform reads the ELF header of file and returns its platform.
+func DetectPlatform(file string) (Platform, error) {
+	r, err := os.Open(file)
+	if err != nil {
+		return Platform{}, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return Platform{}, err
+	}
+
+	return platformFromELF(f, r, file), nil
+}
+
+// HostPlatform returns the platform of the running Go program's target,
+// probing the root filesystem for the libc flavor.
+func HostPlatform() Platform {
+	class, machine := elf.ELFCLASS64, elf.EM_X86_64
+
+	switch goruntime.GOARCH {
+	case "386":
+		class, machine = elf.ELFCLASS32, elf.EM_386
+	case "arm":
+		class, machine = elf.ELFCLASS32, elf.EM_ARM
+	case "arm64":
+		machine = elf.EM_AARCH64
+	case "riscv64":
+		machine = elf.EM_RISCV
+	case "ppc64le":
+		machine = elf.EM_PPC64
+	case "s390x":
+		machine = elf.EM_S390
+	case "loong64":
+		machine = elf.EM_LOONGARCH
+	case "mips64le":
+		machine = elf.EM_MIPS
+	case "mipsle":
+		class, machine = elf.ELFCLASS32, elf.EM_MIPS
+	}
+
+	p := newPlatform(class, machine, elf.ELFDATA2LSB, efARMABIFloatHard, LibcGNU)
+
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		p = newPlatform(class, machine, elf.ELFDATA2LSB, efARMABIFloatHard, LibcMusl)
+	}
+
+	return p
+}
+
+func platformFromELF(f *elf.File, r io.ReaderAt, file string) Platform {
+	libc := LibcUnknown
+
+	for _, prog := range f.Progs {
+		if prog.Type != elf.PT_INTERP {
+			continue
+		}
+
+		data := make([]byte, prog.Filesz)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			break
+		}
+
+		libc = libcFromLoader(filepath.Base(strings.TrimRight(string(data), "\x00")))
+
+		break
+	}
+
+	if libc == LibcUnknown {
+		if libs, err := f.ImportedLibraries(); err == nil {
+			for _, lib := range libs {
+				switch {
+				case strings.HasPrefix(lib, "libc.musl-"):
+					libc = LibcMusl
+				case lib == "libc.so.6":
+					libc = LibcGNU
+				}
+			}
+		}
+	}
+
+	if libc == LibcUnknown {
+		libc = LibcGNU
+	}
+
+	p := newPlatform(f.Class, f.Machine, f.Data, elfFlags(f, r), libc)
+	p.RunPaths = runPaths(f, file)
+
+	return p
+}
+
+func newPlatform(class elf.Class, machine elf.Machine, data elf.Data, flags uint32, libc Libc) Platform {
+	p := Platform{Class: class, Machine: machine, Libc: libc}
+
+	var info *archInfo
+	for i := range archTable {
+		if archTable[i].class == class && archTable[i].machine == machine {
+			info = &archTable[i]
+			break
+		}
+	}
+
+	if info == nil {
+		return p
+	}
+
+	a := *info
+
+	switch {
+	case machine == elf.EM_ARM && flags&efARMABIFloatHard == 0:
+		a.gnuTriplet, a.gnuLoader = "arm-linux-gnueabi", "ld-linux.so.3"
+		a.muslTriplet, a.muslArch = "arm-linux-musleabi", "arm"
+	case machine == elf.EM_PPC64 && data == elf.ELFDATA2MSB:
+		a.gnuTriplet, a.gnuLoader = "powerpc64-linux-gnu", "ld64.so.1"
+		a.muslTriplet, a.muslArch = "powerpc64-linux-musl", "powerpc64"
+	case machine == elf.EM_MIPS && data == elf.ELFDATA2MSB:
+		a.gnuTriplet = strings.Replace(a.gnuTriplet, "el-", "-", 1)
+		a.muslTriplet = strings.Replace(a.muslTriplet, "el-", "-", 1)
+		a.muslArch = strings.TrimSuffix(a.muslArch, "el")
+	}
+
+	if libc == LibcMusl {
+		p.Triplet = a.muslTriplet
+		p.Loader = "ld-musl-" + a.muslArch + ".so.1"
+	} else {
+		p.Triplet = a.gnuTriplet
+		p.Loader = a.gnuLoader
+	}
+
+	return p
+}
+
+// elfFlags returns e_flags, which debug/elf does not expose.
+func elfFlags(f *elf.File, r io.ReaderAt) uint32 {
+	off := int64(0x24)
+	if f.Class == elf.ELFCLASS64 {
+		off = 0x30
+	}
+
+	var buf [4]byte
+	if _, err := r.ReadAt(buf[:], off); err != nil {
+		return 0
+	}
+
+	return f.ByteOrder.Uint32(buf[:])
+}
+
+func libcFromLoader(name string) Libc {
+	switch {
+	case strings.HasPrefix(name, "ld-musl-"):
+		return LibcMusl
+	case strings.HasPrefix(name, "ld-linux"), strings.HasPrefix(name, "ld64.so"), name == "ld.so.1":
+		return LibcGNU
+	default:
+		return LibcUnknown
+	}
+}
+
+func runPaths(f *elf.File, file string) []string {
+	var entries []string
+
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, err := f.DynString(tag)
+		if err != nil {
+			continue
+		}
+
+		for _, value := range values {
+			entries = append(entries, strings.Split(value, ":")...)
+		}
+	}
+
+	origin := filepath.Dir(file)
+	if abs, err := filepath.Abs(origin); err == nil {
+		origin = abs
+	}
+
+	dirs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry == "" {
+			continue
+		}
+
+		entry = strings.ReplaceAll(entry, "${ORIGIN}", origin)
+		entry = strings.ReplaceAll(entry, "$ORIGIN", origin)
+		dirs = append(dirs, filepath.Clean(entry))
+	}
+
+	return dirs
+}
+
+// LibDirs returns the default library directories for p, in search order.
+//
+// The paths are absolute and not filtered for existence.
+func (p Platform) LibDirs() []string {
+	dirs := []string{"/lib", "/usr/lib"}
+
+	if p.Libc == LibcMusl {
+		dirs = append(dirs, "/usr/local/lib")
+	}
+
+	switch p.Class {
+	case elf.ELFCLASS64:
+		dirs = append(dirs, "/lib64", "/usr/lib64")
+	case elf.ELFCLASS32:
+		dirs = append(dirs, "/lib32", "/usr/lib32")
+		if p.Machine == elf.EM_X86_64 {
+			dirs = append(dirs, "/libx32", "/usr/libx32")
+		}
+	}
+
+	if p.Triplet != "" {
+		dirs = append(dirs, "/lib/"+p.Triplet, "/usr/lib/"+p.Triplet)
+	}
+
+	return dirs
+}
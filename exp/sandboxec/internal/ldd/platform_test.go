@@ -0,0 +1,190 @@
+//go:build freebsd || linux
+
+package ldd
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeELF writes a minimal ELF executable header with an optional
+// PT_INTERP segment.
+func writeELF(t *testing.T, class elf.Class, data elf.Data, machine elf.Machine, flags uint32, interp string) string {
+	t.Helper()
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if data == elf.ELFDATA2MSB {
+		order = binary.BigEndian
+	}
+
+	ident := [elf.EI_NIDENT]byte{0x7f, 'E', 'L', 'F', byte(class), byte(data), byte(elf.EV_CURRENT)}
+
+	phnum := uint16(0)
+	if interp != "" {
+		phnum = 1
+		interp += "\x00"
+	}
+
+	var buf bytes.Buffer
+	if class == elf.ELFCLASS64 {
+		hdr := elf.Header64{
+			Ident: ident, Type: uint16(elf.ET_EXEC), Machine: uint16(machine), Version: uint32(elf.EV_CURRENT),
+			Phoff: 64, Flags: flags, Ehsize: 64, Phentsize: 56, Phnum: phnum, Shentsize: 64,
+		}
+		_ = binary.Write(&buf, order, hdr)
+		if phnum > 0 {
+			prog := elf.Prog64{Type: uint32(elf.PT_INTERP), Flags: uint32(elf.PF_R), Off: 64 + 56, Filesz: uint64(len(interp)), Memsz: uint64(len(interp)), Align: 1}
+			_ = binary.Write(&buf, order, prog)
+		}
+	} else {
+		hdr := elf.Header32{
+			Ident: ident, Type: uint16(elf.ET_EXEC), Machine: uint16(machine), Version: uint32(elf.EV_CURRENT),
+			Phoff: 52, Flags: flags, Ehsize: 52, Phentsize: 32, Phnum: phnum, Shentsize: 40,
+		}
+		_ = binary.Write(&buf, order, hdr)
+		if phnum > 0 {
+			prog := elf.Prog32{Type: uint32(elf.PT_INTERP), Flags: uint32(elf.PF_R), Off: 52 + 32, Filesz: uint32(len(interp)), Memsz: uint32(len(interp)), Align: 1}
+			_ = binary.Write(&buf, order, prog)
+		}
+	}
+	buf.WriteString(interp)
+
+	path := filepath.Join(t.TempDir(), "bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0o755); err != nil {
+		t.Fatalf("write ELF fixture: %v", err)
+	}
+
+	return path
+}
+
+func TestDetectPlatform(t *testing.T) {
+	tests := []struct {
+		name        string
+		class       elf.Class
+		data        elf.Data
+		machine     elf.Machine
+		flags       uint32
+		interp      string
+		wantLibc    Libc
+		wantTriplet string
+		wantLoader  string
+	}{
+		{"x86_64 glibc", elf.ELFCLASS64, elf.ELFDATA2LSB, elf.EM_X86_64, 0, "/lib64/ld-linux-x86-64.so.2", LibcGNU, "x86_64-linux-gnu", "ld-linux-x86-64.so.2"},
+		{"x86_64 musl", elf.ELFCLASS64, elf.ELFDATA2LSB, elf.EM_X86_64, 0, "/lib/ld-musl-x86_64.so.1", LibcMusl, "x86_64-linux-musl", "ld-musl-x86_64.so.1"},
+		{"aarch64 glibc", elf.ELFCLASS64, elf.ELFDATA2LSB, elf.EM_AARCH64, 0, "/lib/ld-linux-aarch64.so.1", LibcGNU, "aarch64-linux-gnu", "ld-linux-aarch64.so.1"},
+		{"aarch64 musl", elf.ELFCLASS64, elf.ELFDATA2LSB, elf.EM_AARCH64, 0, "/lib/ld-musl-aarch64.so.1", LibcMusl, "aarch64-linux-musl", "ld-musl-aarch64.so.1"},
+		{"i386 glibc", elf.ELFCLASS32, elf.ELFDATA2LSB, elf.EM_386, 0, "/lib/ld-linux.so.2", LibcGNU, "i386-linux-gnu", "ld-linux.so.2"},
+		{"armhf glibc", elf.ELFCLASS32, elf.ELFDATA2LSB, elf.EM_ARM, efARMABIFloatHard, "/lib/ld-linux-armhf.so.3", LibcGNU, "arm-linux-gnueabihf", "ld-linux-armhf.so.3"},
+		{"armel glibc", elf.ELFCLASS32, elf.ELFDATA2LSB, elf.EM_ARM, 0, "/lib/ld-linux.so.3", LibcGNU, "arm-linux-gnueabi", "ld-linux.so.3"},
+		{"ppc64 big-endian", elf.ELFCLASS64, elf.ELFDATA2MSB, elf.EM_PPC64, 0, "/lib64/ld64.so.1", LibcGNU, "powerpc64-linux-gnu", "ld64.so.1"},
+		{"nix store loader", elf.ELFCLASS64, elf.ELFDATA2LSB, elf.EM_X86_64, 0, "/nix/store/abc-glibc-2.40/lib/ld-linux-x86-64.so.2", LibcGNU, "x86_64-linux-gnu", "ld-linux-x86-64.so.2"},
+		{"static", elf.ELFCLASS64, elf.ELFDATA2LSB, elf.EM_AARCH64, 0, "", LibcGNU, "aarch64-linux-gnu", "ld-linux-aarch64.so.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeELF(t, tt.class, tt.data, tt.machine, tt.flags, tt.interp)
+
+			p, err := DetectPlatform(file)
+			if err != nil {
+				t.Fatalf("DetectPlatform returned error: %v", err)
+			}
+
+			if p.Class != tt.class || p.Machine != tt.machine {
+				t.Fatalf("unexpected header: class=%v machine=%v", p.Class, p.Machine)
+			}
+
+			if p.Libc != tt.wantLibc || p.Triplet != tt.wantTriplet || p.Loader != tt.wantLoader {
+				t.Fatalf("got libc=%v triplet=%q loader=%q, want libc=%v triplet=%q loader=%q",
+					p.Libc, p.Triplet, p.Loader, tt.wantLibc, tt.wantTriplet, tt.wantLoader)
+			}
+		})
+	}
+}
+
+func TestDetectPlatformNotELF(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "script")
+	if err := os.WriteFile(file, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DetectPlatform(file); err == nil {
+		t.Fatalf("expected error for non-ELF file")
+	}
+}
+
+func TestLdSoFixtureSysroots(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		platform Platform
+		want     string
+	}{
+		{
+			name:     "debian aarch64 multiarch",
+			files:    []string{"/lib/aarch64-linux-gnu/ld-linux-aarch64.so.1", "/lib64/ld-linux-x86-64.so.2"},
+			platform: newPlatform(elf.ELFCLASS64, elf.EM_AARCH64, elf.ELFDATA2LSB, 0, LibcGNU),
+			want:     "/lib/aarch64-linux-gnu/ld-linux-aarch64.so.1",
+		},
+		{
+			name:     "x86_64 host with i386 multilib",
+			files:    []string{"/lib64/ld-linux-x86-64.so.2", "/lib32/ld-linux.so.2"},
+			platform: newPlatform(elf.ELFCLASS32, elf.EM_386, elf.ELFDATA2LSB, 0, LibcGNU),
+			want:     "/lib32/ld-linux.so.2",
+		},
+		{
+			name:     "alpine musl",
+			files:    []string{"/lib/ld-musl-x86_64.so.1"},
+			platform: newPlatform(elf.ELFCLASS64, elf.EM_X86_64, elf.ELFDATA2LSB, 0, LibcMusl),
+			want:     "/lib/ld-musl-x86_64.so.1",
+		},
+		{
+			name:  "nixos runpath",
+			files: []string{"/nix/store/abc-glibc-2.40/lib/ld-linux-x86-64.so.2"},
+			platform: func() Platform {
+				p := newPlatform(elf.ELFCLASS64, elf.EM_X86_64, elf.ELFDATA2LSB, 0, LibcGNU)
+				p.RunPaths = []string{"/nix/store/abc-glibc-2.40/lib"}
+				return p
+			}(),
+			want: "/nix/store/abc-glibc-2.40/lib/ld-linux-x86-64.so.2",
+		},
+		{
+			name:     "glob fallback",
+			files:    []string{"/lib64/ld-custom.so.9"},
+			platform: Platform{Class: elf.ELFCLASS64, Machine: elf.EM_SPARCV9},
+			want:     "/lib64/ld-custom.so.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, name := range tt.files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LdSo(root, tt.platform)
+			if err != nil {
+				t.Fatalf("LdSo returned error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("LdSo = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := LdSo(t.TempDir(), newPlatform(elf.ELFCLASS64, elf.EM_AARCH64, elf.ELFDATA2LSB, 0, LibcGNU)); err == nil {
+		t.Fatalf("expected error for empty sysroot")
+	}
+}
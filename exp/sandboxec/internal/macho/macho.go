@@ -7,23 +7,12 @@ import (
 	"debug/macho"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
-	"sync"
-	"time"
 
-	"go.dw1.io/fastcache"
+	"go.dw1.io/x/exp/sandboxec/internal/depcache"
 )
 
-var (
-	depCacheOnce sync.Once
-	depCache     *fastcache.Cache[string, depCacheEntry]
-	depCacheFile string
-
-	depCacheSaveMu     sync.Mutex
-	depCacheLastSaveAt time.Time
-	depCacheWrites     int
-)
+var depCache = depcache.New[depCacheEntry]("macho")
 
 type depCacheEntry struct {
 	Deps []string
@@ -34,24 +23,18 @@ func GetDependencies(root string) ([]string, error) {
 		return nil, nil
 	}
 
-	cache := getDepCache()
-	if key, ok := depCacheKey(root); ok {
-		if cached, found := cache.Get(key); found {
-			return append([]string(nil), cached.Deps...), nil
-		}
-
-		deps, err := getDeps(root)
-		if err != nil {
-			return nil, err
-		}
-
-		cache.Set(key, depCacheEntry{Deps: deps})
-		maybeSaveDepCache()
+	if cached, found := depCache.Get(root); found {
+		return append([]string(nil), cached.Deps...), nil
+	}
 
-		return append([]string(nil), deps...), nil
+	deps, err := getDeps(root)
+	if err != nil {
+		return nil, err
 	}
 
-	return getDeps(root)
+	depCache.Set(root, depCacheEntry{Deps: deps})
+
+	return append([]string(nil), deps...), nil
 }
 
 func getDeps(root string) ([]string, error) {
@@ -119,67 +102,6 @@ func getDeps(root string) ([]string, error) {
 	return deps, nil
 }
 
-func getDepCache() *fastcache.Cache[string, depCacheEntry] {
-	depCacheOnce.Do(func() {
-		cacheDir, err := os.UserCacheDir()
-		if err != nil || cacheDir == "" {
-			depCache = fastcache.New[string, depCacheEntry](16_384)
-			return
-		}
-
-		depCacheFile = filepath.Join(cacheDir, "go.dw1.io", "x", "exp", "sandboxec", "macho", "deps.cache")
-		if err := os.MkdirAll(filepath.Dir(depCacheFile), 0o755); err != nil {
-			depCache = fastcache.New[string, depCacheEntry](16_384)
-			depCacheFile = ""
-			return
-		}
-
-		depCache = fastcache.LoadFromFileOrNew[string, depCacheEntry](depCacheFile, 16_384)
-	})
-
-	return depCache
-}
-
-func depCacheKey(file string) (string, bool) {
-	if file == "" {
-		return "", false
-	}
-
-	info, err := os.Stat(file)
-	if err != nil || !info.Mode().IsRegular() {
-		return "", false
-	}
-
-	var builder strings.Builder
-	builder.Grow(len(file) + 56)
-	builder.WriteString(file)
-	builder.WriteByte('|')
-	builder.WriteString(strconv.FormatInt(info.Size(), 10))
-	builder.WriteByte('|')
-	builder.WriteString(strconv.FormatInt(info.ModTime().UnixNano(), 10))
-
-	return builder.String(), true
-}
-
-func maybeSaveDepCache() {
-	if depCacheFile == "" || depCache == nil {
-		return
-	}
-
-	depCacheSaveMu.Lock()
-	defer depCacheSaveMu.Unlock()
-
-	depCacheWrites++
-	if depCacheWrites < 32 && time.Since(depCacheLastSaveAt) < 2*time.Second {
-		return
-	}
-
-	if err := depCache.SaveToFile(depCacheFile); err == nil {
-		depCacheWrites = 0
-		depCacheLastSaveAt = time.Now()
-	}
-}
-
 func resolveLibraryPath(lib, loaderPath, execDir string, rpaths []string) string {
 	if strings.HasPrefix(lib, "/") {
 		return filepath.Clean(lib)
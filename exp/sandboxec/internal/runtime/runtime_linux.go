@@ -6,6 +6,8 @@ package runtime
 
 import (
 	"bufio"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,38 +15,85 @@ import (
 	"go.dw1.io/x/exp/sandboxec/internal/ldd"
 )
 
-// GetLinkerDirs returns dynamic linker search directories for the host.
+// GetLinkerDirs returns dynamic linker search directories for targets.
 //
-// It starts with existing common system defaults, then appends directories
-// from LD_LIBRARY_PATH and /etc/ld.so.conf (including nested include
-// directives), while preserving discovery order and removing duplicates.
-func GetLinkerDirs() ([]string, error) {
+// The architecture and libc flavor of each target are detected from its ELF
+// header; with no targets (or none that parse), the host platform is used.
+// Directories start with the platform's existing default and multiarch
+// directories plus any DT_RUNPATH entries, then LD_LIBRARY_PATH, then the
+// libc's configuration: /etc/ld.so.conf (including nested include
+// directives) for glibc, or /etc/ld-musl-<arch>.path for musl. Discovery
+// order is preserved and duplicates are removed.
+func GetLinkerDirs(targets ...string) ([]string, error) {
+	platforms := make([]ldd.Platform, 0, len(targets))
+	for _, target := range targets {
+		p, err := ldd.DetectPlatform(target)
+		if err != nil {
+			continue
+		}
+
+		platforms = append(platforms, p)
+	}
+
+	if len(platforms) == 0 {
+		platforms = append(platforms, ldd.HostPlatform())
+	}
+
+	return linkerDirs("/", platforms...)
+}
+
+// linkerDirs implements GetLinkerDirs for the file system rooted at root.
+//
+// Returned paths are absolute within root, that is, root is not part of them.
+func linkerDirs(root string, platforms ...ldd.Platform) ([]string, error) {
 	var dirs []string
 	seen := make(map[string]struct{})
 
-	stdDefaults := []string{
-		"/lib",
-		"/usr/lib",
-		"/lib64",
-		"/usr/lib64",
-		"/lib/x86_64-linux-gnu",
-		"/usr/lib/x86_64-linux-gnu",
-	}
+	for _, p := range platforms {
+		for _, d := range p.RunPaths {
+			dirs = appendExistingRootedDirUniq(dirs, seen, root, d)
+		}
 
-	for _, d := range stdDefaults {
-		dirs = appendExistingDirUniq(dirs, seen, d)
+		for _, d := range p.LibDirs() {
+			dirs = appendExistingRootedDirUniq(dirs, seen, root, d)
+		}
 	}
 
 	for _, d := range splitEnvDirs("LD_LIBRARY_PATH") {
-		dirs = appendExistingDirUniq(dirs, seen, d)
+		dirs = appendExistingRootedDirUniq(dirs, seen, root, d)
 	}
 
-	ldConfDirs, err := parseLdConf("/etc/ld.so.conf")
-	if err != nil {
-		return nil, err
-	}
+	seenConf := make(map[string]struct{})
+	for _, p := range platforms {
+		conf := "/etc/ld.so.conf"
+		if p.Libc == ldd.LibcMusl {
+			arch := strings.TrimSuffix(strings.TrimPrefix(p.Loader, "ld-musl-"), ".so.1")
+			conf = "/etc/ld-musl-" + arch + ".path"
+		}
+
+		if _, ok := seenConf[conf]; ok {
+			continue
+		}
+		seenConf[conf] = struct{}{}
+
+		var confDirs []string
+		var err error
+		if p.Libc == ldd.LibcMusl {
+			confDirs, err = parseMuslPath(root, conf)
+		} else {
+			confDirs, err = parseLdConf(root, conf)
+		}
 
-	dirs = appendUniqWithSeen(dirs, seen, ldConfDirs...)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		dirs = appendUniqWithSeen(dirs, seen, confDirs...)
+	}
 
 	return dirs, nil
 }
@@ -92,12 +141,13 @@ func GetLinkersFilesFromDirs(d ...string) ([]string, error) {
 	return ldd.FList(files...)
 }
 
-// parseLdConf reads an ld.so.conf-style file and returns linker directories.
+// parseLdConf reads an ld.so.conf-style file below root and returns linker
+// directories.
 //
 // It ignores empty lines and comments, resolves include directives recursively,
 // and deduplicates directory entries while preserving discovery order.
-func parseLdConf(filename string) ([]string, error) {
-	file, err := os.Open(filename)
+func parseLdConf(root, filename string) ([]string, error) {
+	file, err := os.Open(filepath.Join(root, filename))
 	if err != nil {
 		return nil, err
 	}
@@ -119,20 +169,23 @@ func parseLdConf(filename string) ([]string, error) {
 
 		if after, ok := strings.CutPrefix(line, "include "); ok {
 			pattern := strings.TrimSpace(after)
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(filepath.Dir(filename), pattern)
+			}
 
-			matches, err := filepath.Glob(pattern)
+			matches, err := filepath.Glob(filepath.Join(root, pattern))
 			if err != nil {
 				continue
 			}
 
 			for _, match := range matches {
-				subDirs, err := parseLdConf(match)
+				subDirs, err := parseLdConf(root, rootRel(root, match))
 				if err != nil {
 					continue
 				}
 
 				for _, d := range subDirs {
-					if _, err := os.Stat(d); err == nil {
+					if _, err := os.Stat(filepath.Join(root, d)); err == nil {
 						dirs = appendUniqWithSeen(dirs, seen, d)
 					}
 				}
@@ -150,3 +203,52 @@ func parseLdConf(filename string) ([]string, error) {
 
 	return dirs, nil
 }
+
+// parseMuslPath reads a musl /etc/ld-musl-<arch>.path file below root.
+//
+// Entries are separated by newlines or colons; the file replaces musl's
+// built-in default path when present.
+func parseMuslPath(root, filename string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, filename))
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	seen := make(map[string]struct{})
+
+	for _, d := range strings.FieldsFunc(string(data), func(r rune) bool {
+		return r == ':' || r == '\n'
+	}) {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+
+		dirs = appendExistingRootedDirUniq(dirs, seen, root, d)
+	}
+
+	return dirs, nil
+}
+
+func appendExistingRootedDirUniq(dirs []string, seen map[string]struct{}, root, dir string) []string {
+	if dir == "" {
+		return dirs
+	}
+
+	info, err := os.Stat(filepath.Join(root, dir))
+	if err != nil || !info.IsDir() {
+		return dirs
+	}
+
+	return appendUniqWithSeen(dirs, seen, dir)
+}
+
+func rootRel(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join("/", rel)
+}
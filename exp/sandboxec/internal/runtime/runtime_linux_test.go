@@ -5,10 +5,14 @@
 package runtime
 
 import (
+	"debug/elf"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"go.dw1.io/x/exp/sandboxec/internal/ldd"
 )
 
 func TestPATHToSOFiles(t *testing.T) {
@@ -228,14 +232,7 @@ func TestGetLinkerDirs(t *testing.T) {
 		seen[d] = struct{}{}
 	}
 
-	stdDefaults := []string{
-		"/lib",
-		"/usr/lib",
-		"/lib64",
-		"/usr/lib64",
-		"/lib/x86_64-linux-gnu",
-		"/usr/lib/x86_64-linux-gnu",
-	}
+	stdDefaults := ldd.HostPlatform().LibDirs()
 
 	expectedPrefix := make([]string, 0, len(stdDefaults))
 	for _, d := range stdDefaults {
@@ -254,3 +251,112 @@ func TestGetLinkerDirs(t *testing.T) {
 		}
 	}
 }
+
+func writeSysroot(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatalf("mkdir %q: %v", path, err)
+			}
+
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir %q: %v", filepath.Dir(path), err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %q: %v", path, err)
+		}
+	}
+
+	return root
+}
+
+func TestLinkerDirsFixtureSysroots(t *testing.T) {
+	t.Setenv("LD_LIBRARY_PATH", "")
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		platform ldd.Platform
+		want     []string
+	}{
+		{
+			name: "debian aarch64",
+			files: map[string]string{
+				"/lib/aarch64-linux-gnu/":           "",
+				"/usr/lib/aarch64-linux-gnu/":       "",
+				"/usr/lib/":                         "",
+				"/lib/x86_64-linux-gnu/":            "",
+				"/etc/ld.so.conf":                   "include /etc/ld.so.conf.d/*.conf\n",
+				"/etc/ld.so.conf.d/a.conf":          "# multiarch support\n/usr/local/lib/aarch64-linux-gnu\n",
+				"/usr/local/lib/aarch64-linux-gnu/": "",
+			},
+			platform: ldd.Platform{Class: elf.ELFCLASS64, Machine: elf.EM_AARCH64, Libc: ldd.LibcGNU, Triplet: "aarch64-linux-gnu", Loader: "ld-linux-aarch64.so.1"},
+			want:     []string{"/lib", "/usr/lib", "/lib/aarch64-linux-gnu", "/usr/lib/aarch64-linux-gnu", "/usr/local/lib/aarch64-linux-gnu"},
+		},
+		{
+			name: "i386 multilib",
+			files: map[string]string{
+				"/lib/":                  "",
+				"/lib64/":                "",
+				"/lib32/":                "",
+				"/usr/lib32/":            "",
+				"/lib/i386-linux-gnu/":   "",
+				"/lib/x86_64-linux-gnu/": "",
+			},
+			platform: ldd.Platform{Class: elf.ELFCLASS32, Machine: elf.EM_386, Libc: ldd.LibcGNU, Triplet: "i386-linux-gnu", Loader: "ld-linux.so.2"},
+			want:     []string{"/lib", "/lib32", "/usr/lib32", "/lib/i386-linux-gnu"},
+		},
+		{
+			name: "alpine musl",
+			files: map[string]string{
+				"/lib/":                    "",
+				"/usr/lib/":                "",
+				"/usr/local/lib/":          "",
+				"/opt/extra/lib/":          "",
+				"/lib/ld-musl-x86_64.so.1": "",
+				"/etc/ld-musl-x86_64.path": "/lib:/usr/local/lib\n/opt/extra/lib\n/missing\n",
+			},
+			platform: ldd.Platform{Class: elf.ELFCLASS64, Machine: elf.EM_X86_64, Libc: ldd.LibcMusl, Triplet: "x86_64-linux-musl", Loader: "ld-musl-x86_64.so.1"},
+			want:     []string{"/lib", "/usr/lib", "/usr/local/lib", "/opt/extra/lib"},
+		},
+		{
+			name: "nixos runpath",
+			files: map[string]string{
+				"/nix/store/abc-glibc-2.40/lib/ld-linux-x86-64.so.2": "",
+				"/nix/store/def-zlib-1.3/lib/":                       "",
+			},
+			platform: ldd.Platform{
+				Class:    elf.ELFCLASS64,
+				Machine:  elf.EM_X86_64,
+				Libc:     ldd.LibcGNU,
+				Triplet:  "x86_64-linux-gnu",
+				Loader:   "ld-linux-x86-64.so.2",
+				RunPaths: []string{"/nix/store/abc-glibc-2.40/lib", "/nix/store/def-zlib-1.3/lib"},
+			},
+			want: []string{"/nix/store/abc-glibc-2.40/lib", "/nix/store/def-zlib-1.3/lib"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeSysroot(t, tt.files)
+
+			got, err := linkerDirs(root, tt.platform)
+			if err != nil {
+				t.Fatalf("linkerDirs returned error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("unexpected linker dirs: got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+// NetworkMode describes how network access is restricted after enforcement.
+type NetworkMode int
+
+const (
+	// NetworkUnrestricted means network access is not restricted, for
+	// example on Landlock ABI V1-V3 without [WithSeccompNetworkFallback].
+	NetworkUnrestricted NetworkMode = iota
+
+	// NetworkLandlock means TCP bind and connect are restricted by Landlock
+	// (ABI V4+), allowing only ports added with WithNetworkRule.
+	NetworkLandlock
+
+	// NetworkSeccompDeny means creating IPv4 and IPv6 sockets is denied by
+	// the seccomp fallback. This is coarse: no port can be allowed.
+	NetworkSeccompDeny
+
+	// NetworkSeatbelt means network access is restricted by the Darwin
+	// Seatbelt policy.
+	NetworkSeatbelt
+)
+
+// String returns a short name for m.
+func (m NetworkMode) String() string {
+	switch m {
+	case NetworkLandlock:
+		return "landlock"
+	case NetworkSeccompDeny:
+		return "seccomp-deny"
+	case NetworkSeatbelt:
+		return "seatbelt"
+	default:
+		return "unrestricted"
+	}
+}
+
+// NetworkMode reports how network access was restricted by enforcement.
+//
+// It returns NetworkUnrestricted before enforcement or when enforcement
+// failed.
+func (s *Sandboxec) NetworkMode() NetworkMode {
+	return s.netMode
+}
@@ -17,11 +17,15 @@ import (
 type Option func(*config) error
 
 type config struct {
-	bestEffort bool
-	flags      uint64
+	bestEffort        bool
+	unsafeHostRuntime bool
+	flags             uint64
 
 	fsRules  []fsRule
 	netRules []netRule
+	proc     procConfig
+	cache    cacheConfig
+	hooks    hookConfig
 }
 
 func defaultConfig() config {
@@ -98,39 +102,87 @@ func WithRestrictScoped() Option {
 	}
 }
 
+// WithScopeAbstractUnixSocket is unsupported on Darwin.
+func WithScopeAbstractUnixSocket() Option {
+	return func(cfg *config) error {
+		_ = cfg
+
+		return fmt.Errorf("%w: WithScopeAbstractUnixSocket is unsupported on darwin", ErrInvalidOption)
+	}
+}
+
+// WithScopeSignal is unsupported on Darwin.
+func WithScopeSignal() Option {
+	return func(cfg *config) error {
+		_ = cfg
+
+		return fmt.Errorf("%w: WithScopeSignal is unsupported on darwin", ErrInvalidOption)
+	}
+}
+
+// WithSeccompNetworkFallback is unsupported on Darwin.
+func WithSeccompNetworkFallback() Option {
+	return func(cfg *config) error {
+		_ = cfg
+
+		return fmt.Errorf("%w: WithSeccompNetworkFallback is unsupported on darwin", ErrInvalidOption)
+	}
+}
+
+// WithRootfs is unsupported on Darwin.
+func WithRootfs(binds ...Bind) Option {
+	return func(cfg *config) error {
+		_ = cfg
+
+		return fmt.Errorf("%w: WithRootfs is unsupported on darwin", ErrInvalidOption)
+	}
+}
+
 // WithUnsafeHostRuntime allows [access.FS_READ_EXEC] access to host runtime paths.
 //
 // It grants read/execute rights to PATH-derived runtime targets and to
 // resolved shared-library dependency files discovered from executable entries.
 // Use it for compatibility with host-provided runtimes and shared libraries.
 // It may broaden sandbox access.
+//
+// Discovery runs at enforcement time, using the dependency cache configured
+// by [WithDependencyCache] or [WithoutDependencyCache].
 func WithUnsafeHostRuntime() Option {
 	return func(cfg *config) error {
-		pathTargets := runtime.GetPATHDirs()
+		cfg.unsafeHostRuntime = true
 
-		soFiles, err := runtime.GetLinkersFilesFromDirs(pathTargets...)
-		if err != nil {
-			return fmt.Errorf("%w: failed to resolve runtime dependency files: %v", ErrSeatbeltUnavailable, err)
-		}
-		pathTargets = append(pathTargets, soFiles...)
+		return nil
+	}
+}
 
-		seen := make(map[string]struct{})
-		for _, pathTarget := range pathTargets {
-			if pathTarget == "" {
-				continue
-			}
+// resolveHostRuntime appends the rules requested by WithUnsafeHostRuntime.
+func (c *config) resolveHostRuntime() error {
+	defer c.cache.scope(c.fsRules)()
 
-			cleaned := filepath.Clean(pathTarget)
-			if _, ok := seen[cleaned]; ok {
-				continue
-			}
+	pathTargets := runtime.GetPATHDirs()
+
+	soFiles, err := runtime.GetLinkersFilesFromDirs(pathTargets...)
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve runtime dependency files: %v", ErrSeatbeltUnavailable, err)
+	}
+	pathTargets = append(pathTargets, soFiles...)
 
-			seen[cleaned] = struct{}{}
-			cfg.fsRules = append(cfg.fsRules, fsRule{path: cleaned, rights: access.FS_READ_EXEC})
+	seen := make(map[string]struct{})
+	for _, pathTarget := range pathTargets {
+		if pathTarget == "" {
+			continue
 		}
 
-		return nil
+		cleaned := filepath.Clean(pathTarget)
+		if _, ok := seen[cleaned]; ok {
+			continue
+		}
+
+		seen[cleaned] = struct{}{}
+		c.fsRules = append(c.fsRules, fsRule{path: cleaned, rights: access.FS_READ_EXEC})
 	}
+
+	return nil
 }
 
 func (c config) seatbeltPolicy() string {
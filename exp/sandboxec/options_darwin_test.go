@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 
 	"go.dw1.io/x/exp/sandboxec/access"
@@ -22,6 +23,11 @@ func TestDarwinUnsupportedOptionErrors(t *testing.T) {
 		{name: "WithABI", opt: WithABI(1)},
 		{name: "WithIgnoreIfMissing", opt: WithIgnoreIfMissing()},
 		{name: "WithRestrictScoped", opt: WithRestrictScoped()},
+		{name: "WithScopeAbstractUnixSocket", opt: WithScopeAbstractUnixSocket()},
+		{name: "WithScopeSignal", opt: WithScopeSignal()},
+		{name: "WithRootfs", opt: WithRootfs(ReadOnlyBind("/usr"))},
+		{name: "WithSeccompNetworkFallback", opt: WithSeccompNetworkFallback()},
+		{name: "WithParentDeathSignal", opt: WithParentDeathSignal(syscall.SIGKILL)},
 	}
 
 	for _, tt := range tests {
@@ -94,6 +100,14 @@ func TestDarwinWithUnsafeHostRuntimeDedupesAndNormalizes(t *testing.T) {
 		t.Fatalf("WithUnsafeHostRuntime returned error: %v", err)
 	}
 
+	if !cfg.unsafeHostRuntime || len(cfg.fsRules) != 0 {
+		t.Fatalf("expected discovery to be deferred to enforcement, got %+v", cfg.fsRules)
+	}
+
+	if err := cfg.resolveHostRuntime(); err != nil {
+		t.Fatalf("resolveHostRuntime returned error: %v", err)
+	}
+
 	if len(cfg.fsRules) != 1 {
 		t.Fatalf("expected one deduped fs rule, got %d: %+v", len(cfg.fsRules), cfg.fsRules)
 	}
@@ -6,6 +6,7 @@ package sandboxec
 
 import (
 	"fmt"
+	goruntime "runtime"
 	"sync"
 
 	"github.com/landlock-lsm/go-landlock/landlock"
@@ -21,12 +22,18 @@ import (
 type Option func(*config) error
 
 type config struct {
-	abi             int
-	bestEffort      bool
-	ignoreIfMissing bool
-	restrictScoped  bool
-	fsRules         []fsRule
-	netRules        []netRule
+	abi                int
+	bestEffort         bool
+	ignoreIfMissing    bool
+	scoped             landlock.ScopedSet
+	seccompNetFallback bool
+	unsafeHostRuntime  bool
+	fsRules            []fsRule
+	netRules           []netRule
+	proc               procConfig
+	cache              cacheConfig
+	rootfs             *rootfsConfig
+	hooks              hookConfig
 }
 
 const maxABIVersion = 7
@@ -92,8 +99,10 @@ func (c *config) validateCompatibility() error {
 		return fmt.Errorf("%w: network rules require ABI V4+", ErrABINotSupported)
 	}
 
-	if c.abi < 6 && c.restrictScoped {
-		return fmt.Errorf("%w: scoped IPC restrictions require ABI V6", ErrABINotSupported)
+	for _, scope := range scopeTable {
+		if c.scoped&scope.set != 0 && c.abi < scope.abi {
+			return fmt.Errorf("%w: %s scope restriction requires ABI V%d", ErrABINotSupported, scope.name, scope.abi)
+		}
 	}
 
 	if c.bestEffort {
@@ -202,12 +211,61 @@ func WithNetworkRule(port uint16, rights access.Network) Option {
 	}
 }
 
-// WithRestrictScoped enables scoped IPC restrictions (Landlock V6+).
+// WithRestrictScoped enables all scoped IPC restrictions (Landlock V6+).
 //
-// This further limits the process to scoped IPC operations when supported.
+// It is equivalent to passing both [WithScopeAbstractUnixSocket] and
+// [WithScopeSignal].
 func WithRestrictScoped() Option {
 	return func(cfg *config) error {
-		cfg.restrictScoped = true
+		cfg.scoped |= scopeAbstractUnixSocket | scopeSignal
+
+		return nil
+	}
+}
+
+// WithScopeAbstractUnixSocket blocks connections to abstract UNIX sockets
+// created outside the sandbox domain (Landlock V6+).
+//
+// This covers sockets such as the abstract D-Bus and X11 endpoints. Pathname
+// UNIX sockets are governed by filesystem rules instead.
+func WithScopeAbstractUnixSocket() Option {
+	return func(cfg *config) error {
+		cfg.scoped |= scopeAbstractUnixSocket
+
+		return nil
+	}
+}
+
+// WithScopeSignal blocks sending signals to processes outside the sandbox
+// domain (Landlock V6+).
+//
+// Processes started inside the domain, including helpers spawned by the
+// sandboxed command, can still be signaled.
+func WithScopeSignal() Option {
+	return func(cfg *config) error {
+		cfg.scoped |= scopeSignal
+
+		return nil
+	}
+}
+
+// WithSeccompNetworkFallback denies IPv4 and IPv6 sockets with a seccomp
+// filter when Landlock cannot restrict the network.
+//
+// Landlock restricts TCP only from ABI V4. With this option, when the
+// effective ABI is below V4 and no WithNetworkRule is configured, a seccomp
+// filter makes socket(2) fail with EACCES for AF_INET and AF_INET6, giving a
+// coarse "no network" mode on older kernels. UNIX sockets and sockets
+// inherited from the parent still work, and on architectures with
+// socketcall(2) that syscall is denied entirely. On ABI V4+ it has no
+// effect. [Sandboxec.NetworkMode] reports which mode was applied.
+func WithSeccompNetworkFallback() Option {
+	return func(cfg *config) error {
+		if _, ok := seccompArchTable[goruntime.GOARCH]; !ok {
+			return fmt.Errorf("%w: SeccompNetworkFallback is unsupported on %s", ErrInvalidOption, goruntime.GOARCH)
+		}
+
+		cfg.seccompNetFallback = true
 
 		return nil
 	}
@@ -219,22 +277,50 @@ func WithRestrictScoped() Option {
 // resolved shared-library dependency files discovered from executable entries.
 // Use it for compatibility with host-provided runtimes and shared libraries.
 // It may broaden sandbox access.
+//
+// Discovery runs at enforcement time, using the dependency cache configured
+// by [WithDependencyCache] or [WithoutDependencyCache].
 func WithUnsafeHostRuntime() Option {
 	return func(cfg *config) error {
-		pathTargets := runtime.GetPATHDirs()
+		cfg.unsafeHostRuntime = true
 
-		soFiles, err := runtime.GetLinkersFilesFromDirs(pathTargets...)
-		if err != nil {
-			return fmt.Errorf("%w: failed to resolve runtime dependency files: %v", ErrLandlockUnavailable, err)
-		}
-		pathTargets = append(pathTargets, soFiles...)
+		return nil
+	}
+}
 
-		for _, pathTarget := range pathTargets {
-			cfg.fsRules = append(cfg.fsRules, fsRule{path: pathTarget, rights: access.FS_READ_EXEC})
-		}
+// resolveHostRuntime appends the rules requested by WithUnsafeHostRuntime.
+func (c *config) resolveHostRuntime() error {
+	defer c.cache.scope(c.fsRules)()
 
-		return nil
+	pathTargets := runtime.GetPATHDirs()
+
+	soFiles, err := runtime.GetLinkersFilesFromDirs(pathTargets...)
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve runtime dependency files: %v", ErrLandlockUnavailable, err)
 	}
+	pathTargets = append(pathTargets, soFiles...)
+
+	for _, pathTarget := range pathTargets {
+		c.fsRules = append(c.fsRules, fsRule{path: pathTarget, rights: access.FS_READ_EXEC})
+	}
+
+	return nil
+}
+
+const (
+	scopeAbstractUnixSocket = landlock.ScopedSet(syscall.ScopeAbstractUnixSocket)
+	scopeSignal             = landlock.ScopedSet(syscall.ScopeSignal)
+)
+
+// scopeTable lists the scoped IPC restrictions in Landlock flag order, with
+// the ABI that introduced each one.
+var scopeTable = []struct {
+	set  landlock.ScopedSet
+	name string
+	abi  int
+}{
+	{scopeAbstractUnixSocket, "abstract_unix_socket", 6},
+	{scopeSignal, "signal", 6},
 }
 
 type fsRule struct {
@@ -5,11 +5,21 @@
 package sandboxec
 
 import (
+	"bufio"
+	"context"
 	"errors"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"testing"
+	"time"
 
 	"go.dw1.io/x/exp/sandboxec/access"
+	"go.dw1.io/x/exp/sandboxec/internal/depcache"
+	"golang.org/x/sys/unix"
 )
 
 func setLandlockABICacheForTest(version int, err error) {
@@ -96,7 +106,7 @@ func TestLinuxValidateCompatibility(t *testing.T) {
 		t.Fatalf("expected ErrABINotSupported for network rule on ABI 3, got %v", err)
 	}
 
-	cfg = config{abi: 5, restrictScoped: true}
+	cfg = config{abi: 5, scoped: scopeSignal}
 	if err := cfg.validateCompatibility(); !errors.Is(err, ErrABINotSupported) {
 		t.Fatalf("expected ErrABINotSupported for scoped restriction on ABI 5, got %v", err)
 	}
@@ -156,3 +166,337 @@ func TestLinuxRuleOptionsValidation(t *testing.T) {
 		t.Fatalf("unexpected netRules contents: %+v", cfg.netRules)
 	}
 }
+
+func TestLinuxProcessOptions(t *testing.T) {
+	cfg := defaultConfig()
+
+	if err := WithKillProcessTree(-time.Second)(&cfg); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for negative grace, got %v", err)
+	}
+
+	if err := WithWaitDelay(-time.Second)(&cfg); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for negative wait delay, got %v", err)
+	}
+
+	if err := WithParentDeathSignal(0)(&cfg); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for zero signal, got %v", err)
+	}
+
+	for _, opt := range []Option{
+		WithKillProcessTree(time.Second),
+		WithWaitDelay(2 * time.Second),
+		WithParentDeathSignal(syscall.SIGKILL),
+	} {
+		if err := opt(&cfg); err != nil {
+			t.Fatalf("process option returned error: %v", err)
+		}
+	}
+
+	if !cfg.proc.processGroup || !cfg.proc.killTree || cfg.proc.killGrace != time.Second {
+		t.Fatalf("unexpected process config: %+v", cfg.proc)
+	}
+
+	cmd := exec.Command("/bin/true")
+	cfg.proc.apply(cmd, false)
+	if cmd.Cancel != nil {
+		t.Fatalf("expected no Cancel hook for command without context")
+	}
+
+	cmd = exec.CommandContext(context.Background(), "/bin/true")
+	cfg.proc.apply(cmd, true)
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.Setpgid || cmd.SysProcAttr.Pdeathsig != syscall.SIGKILL {
+		t.Fatalf("unexpected SysProcAttr: %+v", cmd.SysProcAttr)
+	}
+	if cmd.WaitDelay != 2*time.Second {
+		t.Fatalf("WaitDelay = %v, want %v", cmd.WaitDelay, 2*time.Second)
+	}
+	if cmd.Cancel == nil {
+		t.Fatalf("expected Cancel hook for command with context")
+	}
+
+	cfg.proc.waitDelay = 0
+	cmd = exec.CommandContext(context.Background(), "/bin/true")
+	cfg.proc.apply(cmd, true)
+	if want := time.Second + killTreeWaitMargin; cmd.WaitDelay != want {
+		t.Fatalf("default WaitDelay = %v, want %v", cmd.WaitDelay, want)
+	}
+}
+
+func TestKillProcessGroupEscalation(t *testing.T) {
+	// A leader ignoring SIGTERM is killed once grace elapses.
+	cmd := exec.Command("/bin/sh", "-c", `trap "" TERM; echo ready; while :; do /bin/sleep 1; done`)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := stdout.Read(make([]byte, 8)); err != nil {
+		t.Fatalf("wait for trap: %v", err)
+	}
+
+	if err := killProcessGroup(cmd.Process, 50*time.Millisecond); err != nil {
+		t.Fatalf("killProcessGroup: %v", err)
+	}
+
+	_ = cmd.Wait()
+	status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() || status.Signal() != syscall.SIGKILL {
+		t.Fatalf("expected SIGKILL after grace, got %v", cmd.ProcessState)
+	}
+
+	// A grandchild ignoring SIGTERM is killed even though the leader exited
+	// on SIGTERM and was waited for before grace elapsed.
+	cmd = exec.Command("/bin/sh", "-c", `/bin/sh -c 'trap "" TERM; echo $$; exec /bin/sleep 30' & wait`)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if stdout, err = cmd.StdoutPipe(); err != nil {
+		t.Fatalf("stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read grandchild pid: %v", err)
+	}
+	grandchild, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		t.Fatalf("parse grandchild pid: %v", err)
+	}
+
+	if err := killProcessGroup(cmd.Process, 100*time.Millisecond); err != nil {
+		t.Fatalf("killProcessGroup: %v", err)
+	}
+	_ = cmd.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(grandchild) {
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(grandchild, syscall.SIGKILL)
+			t.Fatalf("grandchild %d ignoring SIGTERM survived the grace period", grandchild)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestLinuxDependencyCacheOptions(t *testing.T) {
+	t.Cleanup(func() {
+		depcache.Configure(depcache.Config{})
+	})
+
+	cfg := defaultConfig()
+
+	if err := WithDependencyCache("")(&cfg); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for empty cache dir, got %v", err)
+	}
+
+	writable := t.TempDir()
+	cacheDir := filepath.Join(writable, "cache")
+
+	for _, opt := range []Option{
+		WithDependencyCache(cacheDir),
+		WithDependencyCacheContentHash(),
+		WithUnsafeHostRuntime(),
+	} {
+		if err := opt(&cfg); err != nil {
+			t.Fatalf("cache option returned error: %v", err)
+		}
+	}
+
+	if !cfg.unsafeHostRuntime || len(cfg.fsRules) != 0 {
+		t.Fatalf("expected host runtime discovery to be deferred, got %+v", cfg.fsRules)
+	}
+
+	end := cfg.cache.scope([]fsRule{{path: writable, rights: access.FS_READ_EXEC}})
+	got := depcache.Current()
+	end()
+	if got.MemoryOnly || got.Dir != cacheDir || !got.HashContent {
+		t.Fatalf("unexpected cache config for read-only rule: %+v", got)
+	}
+
+	if got := depcache.Current(); got != (depcache.Config{}) {
+		t.Fatalf("expected the scope to restore the cache config, got %+v", got)
+	}
+
+	end = cfg.cache.scope([]fsRule{{path: writable, rights: access.FS_READ_WRITE}})
+	got = depcache.Current()
+	end()
+	if !got.MemoryOnly {
+		t.Fatalf("expected memory-only cache inside writable rule, got %+v", got)
+	}
+
+	// Another instance using the exposed directory does not load it either.
+	other := defaultConfig()
+	if err := WithDependencyCache(cacheDir)(&other); err != nil {
+		t.Fatalf("WithDependencyCache returned error: %v", err)
+	}
+
+	end = other.cache.scope(nil)
+	got = depcache.Current()
+	end()
+	if !got.MemoryOnly || got.HashContent {
+		t.Fatalf("expected memory-only cache for an exposed directory, got %+v", got)
+	}
+
+	if err := WithoutDependencyCache()(&cfg); err != nil {
+		t.Fatalf("WithoutDependencyCache returned error: %v", err)
+	}
+
+	end = cfg.cache.scope(nil)
+	got = depcache.Current()
+	end()
+	if !got.Disabled {
+		t.Fatalf("expected disabled cache, got %+v", got)
+	}
+}
+
+func TestLinuxScopeOptions(t *testing.T) {
+	t.Cleanup(resetLandlockABICacheForTest)
+
+	cfg := defaultConfig()
+	if err := WithScopeSignal()(&cfg); err != nil {
+		t.Fatalf("WithScopeSignal returned error: %v", err)
+	}
+
+	if cfg.scoped != scopeSignal {
+		t.Fatalf("scoped = %v, want signal only", cfg.scoped)
+	}
+
+	if err := WithScopeAbstractUnixSocket()(&cfg); err != nil {
+		t.Fatalf("WithScopeAbstractUnixSocket returned error: %v", err)
+	}
+
+	restricted := defaultConfig()
+	if err := WithRestrictScoped()(&restricted); err != nil {
+		t.Fatalf("WithRestrictScoped returned error: %v", err)
+	}
+
+	if cfg.scoped != restricted.scoped {
+		t.Fatalf("separate scopes = %v, want %v", cfg.scoped, restricted.scoped)
+	}
+
+	cfg = config{abi: 5, scoped: scopeAbstractUnixSocket}
+	err := cfg.validateCompatibility()
+	if !errors.Is(err, ErrABINotSupported) || !strings.Contains(err.Error(), "abstract_unix_socket") {
+		t.Fatalf("expected per-scope ErrABINotSupported, got %v", err)
+	}
+
+	setLandlockABICacheForTest(5, nil)
+	cfg = config{abi: 6, bestEffort: true, scoped: scopeSignal}
+	if got := cfg.scopeStatus(); len(got) != 1 || got[0].Name != "signal" || got[0].Enforced {
+		t.Fatalf("unexpected best-effort status on ABI 5 kernel: %+v", got)
+	}
+
+	setLandlockABICacheForTest(6, nil)
+	cfg.scoped = scopeAbstractUnixSocket | scopeSignal
+	got := cfg.scopeStatus()
+	if len(got) != 2 || got[0].Name != "abstract_unix_socket" || !got[0].Enforced || !got[1].Enforced {
+		t.Fatalf("unexpected status on ABI 6 kernel: %+v", got)
+	}
+}
+
+func TestLinuxRootfsOptions(t *testing.T) {
+	cfg := defaultConfig()
+
+	if err := WithRootfs(ReadOnlyBind("relative"))(&cfg); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for relative source, got %v", err)
+	}
+
+	if err := WithRootfs(Bind{Source: "/srv", Target: "srv"})(&cfg); !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption for relative target, got %v", err)
+	}
+
+	if cfg.rootfs != nil {
+		t.Fatalf("expected invalid binds to leave rootfs unset, got %+v", cfg.rootfs)
+	}
+
+	if err := WithRootfs(ReadOnlyBind("/usr/"), ReadWriteBind("/tmp/work"))(&cfg); err != nil {
+		t.Fatalf("WithRootfs returned error: %v", err)
+	}
+
+	if err := WithRootfs(Bind{Source: "/srv/data", Target: "/data/"})(&cfg); err != nil {
+		t.Fatalf("WithRootfs returned error: %v", err)
+	}
+
+	want := []Bind{
+		{Source: "/usr", Target: "/usr"},
+		{Source: "/tmp/work", Target: "/tmp/work", Writable: true},
+		{Source: "/srv/data", Target: "/data"},
+	}
+
+	if cfg.rootfs == nil || len(cfg.rootfs.binds) != len(want) {
+		t.Fatalf("unexpected binds: %+v", cfg.rootfs)
+	}
+
+	for i, bind := range cfg.rootfs.binds {
+		if bind != want[i] {
+			t.Fatalf("bind %d = %+v, want %+v", i, bind, want[i])
+		}
+	}
+
+	cmd := exec.Command("/bin/true", "arg")
+	cfg.rootfs.wrap(cmd, &cfg)
+	if cmd.Err != nil {
+		t.Fatalf("wrap returned error: %v", cmd.Err)
+	}
+
+	if cmd.Path != "/proc/self/exe" || len(cmd.Args) != 5 || cmd.Args[0] != rootfsInitArg0 || cmd.Args[2] != "/bin/true" || cmd.Args[4] != "arg" {
+		t.Fatalf("unexpected wrapped command: %q %q", cmd.Path, cmd.Args)
+	}
+
+	const flags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID
+	if cmd.SysProcAttr == nil || cmd.SysProcAttr.Cloneflags&flags != flags {
+		t.Fatalf("expected new user, mount and PID namespaces, got %+v", cmd.SysProcAttr)
+	}
+}
+
+func TestLinuxNetworkMode(t *testing.T) {
+	t.Cleanup(resetLandlockABICacheForTest)
+
+	setLandlockABICacheForTest(3, nil)
+
+	tests := []struct {
+		name string
+		cfg  config
+		want NetworkMode
+	}{
+		{name: "abi4", cfg: config{abi: 4}, want: NetworkLandlock},
+		{name: "abi3", cfg: config{abi: 3}, want: NetworkUnrestricted},
+		{name: "abi3-fallback", cfg: config{abi: 3, seccompNetFallback: true}, want: NetworkSeccompDeny},
+		{name: "best-effort-downgrade", cfg: config{abi: 6, bestEffort: true, seccompNetFallback: true}, want: NetworkSeccompDeny},
+		{name: "best-effort-no-fallback", cfg: config{abi: 6, bestEffort: true}, want: NetworkUnrestricted},
+		{name: "fallback-with-net-rules", cfg: config{abi: 3, seccompNetFallback: true, netRules: []netRule{{port: 443, rights: access.NETWORK_CONNECT_TCP}}}, want: NetworkUnrestricted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.networkMode(); got != tt.want {
+				t.Fatalf("networkMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeccompNetworkFilterLayout(t *testing.T) {
+	for arch, arches := range seccompArchTable {
+		prog := seccompNetworkFilter(arches)
+
+		last := prog[len(prog)-1]
+		if last.Code != unix.BPF_RET|unix.BPF_K || last.K&unix.SECCOMP_RET_ACTION_FULL != unix.SECCOMP_RET_ERRNO {
+			t.Fatalf("%s: expected unknown architectures to be denied, got %+v", arch, last)
+		}
+
+		for i, insn := range prog {
+			if insn.Code&0x07 != unix.BPF_JMP {
+				continue
+			}
+
+			if i+1+int(insn.Jt) >= len(prog) || i+1+int(insn.Jf) >= len(prog) {
+				t.Fatalf("%s: jump at %d leaves the program", arch, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// procConfig controls the lifecycle of processes started from a Sandboxec.
+type procConfig struct {
+	processGroup bool
+	killTree     bool
+	killGrace    time.Duration
+	waitDelay    time.Duration
+	pdeathsig    syscall.Signal
+}
+
+// killTreeWaitMargin is added to the kill grace period to derive the default
+// WaitDelay of commands killed with [WithKillProcessTree].
+const killTreeWaitMargin = time.Second
+
+// WithProcessGroup starts each command in its own process group.
+//
+// The command becomes the leader of a new process group, so the whole tree it
+// spawns can be signaled at once. Descendants that call setsid(2) or
+// setpgid(2) themselves leave the group.
+func WithProcessGroup() Option {
+	return func(cfg *config) error {
+		cfg.proc.processGroup = true
+
+		return nil
+	}
+}
+
+// WithKillProcessTree kills the command's whole process group when the
+// context passed to CommandContext is done.
+//
+// The group is sent SIGTERM first and SIGKILL after grace elapses, so
+// descendants that ignore SIGTERM are killed even when the command itself
+// already exited. A zero grace sends SIGKILL right away. Unless
+// [WithWaitDelay] is set, the command's WaitDelay defaults to grace plus one
+// second, so Wait does not block on pipes held by surviving descendants. It
+// implies [WithProcessGroup]. Commands created with Command are not affected,
+// since they have no context.
+func WithKillProcessTree(grace time.Duration) Option {
+	return func(cfg *config) error {
+		if grace < 0 {
+			return fmt.Errorf("%w: KillProcessTree requires a non-negative grace period", ErrInvalidOption)
+		}
+
+		cfg.proc.processGroup = true
+		cfg.proc.killTree = true
+		cfg.proc.killGrace = grace
+
+		return nil
+	}
+}
+
+// WithWaitDelay sets the default [exec.Cmd] WaitDelay for created commands.
+//
+// Callers can still override WaitDelay on individual commands before Start.
+func WithWaitDelay(d time.Duration) Option {
+	return func(cfg *config) error {
+		if d < 0 {
+			return fmt.Errorf("%w: WaitDelay requires a non-negative duration", ErrInvalidOption)
+		}
+
+		cfg.proc.waitDelay = d
+
+		return nil
+	}
+}
+
+func (p procConfig) apply(cmd *exec.Cmd, hasContext bool) {
+	if p.waitDelay > 0 {
+		cmd.WaitDelay = p.waitDelay
+	} else if p.killTree && hasContext {
+		cmd.WaitDelay = p.killGrace + killTreeWaitMargin
+	}
+
+	if p.processGroup || p.pdeathsig != 0 {
+		if cmd.SysProcAttr == nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+		}
+
+		if p.processGroup {
+			cmd.SysProcAttr.Setpgid = true
+			cmd.SysProcAttr.Pgid = 0
+		}
+
+		setPdeathsig(cmd.SysProcAttr, p.pdeathsig)
+	}
+
+	if p.killTree && hasContext {
+		grace := p.killGrace
+		cmd.Cancel = func() error {
+			return killProcessGroup(cmd.Process, grace)
+		}
+	}
+}
+
+// killProcessGroup signals the process group led by proc, escalating from
+// SIGTERM to SIGKILL after grace.
+func killProcessGroup(proc *os.Process, grace time.Duration) error {
+	if proc == nil {
+		return os.ErrProcessDone
+	}
+
+	pgid := -proc.Pid
+
+	sig := syscall.SIGTERM
+	if grace == 0 {
+		sig = syscall.SIGKILL
+	}
+
+	if err := syscall.Kill(pgid, sig); err != nil {
+		if errors.Is(err, syscall.ESRCH) {
+			return os.ErrProcessDone
+		}
+
+		return err
+	}
+
+	if sig != syscall.SIGKILL {
+		time.AfterFunc(grace, func() {
+			// The group ID stays reserved while any member is alive, so this
+			// reaches descendants even after the leader was waited for.
+			_ = syscall.Kill(pgid, syscall.SIGKILL)
+		})
+	}
+
+	return nil
+}
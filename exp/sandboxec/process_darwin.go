@@ -0,0 +1,25 @@
+// nolint
+//go:build darwin
+// +build darwin
+
+package sandboxec
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// WithParentDeathSignal is unsupported on Darwin.
+func WithParentDeathSignal(sig syscall.Signal) Option {
+	return func(cfg *config) error {
+		_ = cfg
+		_ = sig
+
+		return fmt.Errorf("%w: WithParentDeathSignal is unsupported on darwin", ErrInvalidOption)
+	}
+}
+
+func setPdeathsig(attr *syscall.SysProcAttr, sig syscall.Signal) {
+	_ = attr
+	_ = sig
+}
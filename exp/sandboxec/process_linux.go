@@ -0,0 +1,34 @@
+// nolint
+//go:build linux
+// +build linux
+
+package sandboxec
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// WithParentDeathSignal asks the kernel to send sig to each command when the
+// creating thread of the current process dies.
+//
+// This lets children die with the parent when it crashes. Note that the
+// signal is tied to the OS thread that started the command, not the whole
+// process; see PR_SET_PDEATHSIG in prctl(2).
+func WithParentDeathSignal(sig syscall.Signal) Option {
+	return func(cfg *config) error {
+		if sig <= 0 {
+			return fmt.Errorf("%w: ParentDeathSignal requires a valid signal", ErrInvalidOption)
+		}
+
+		cfg.proc.pdeathsig = sig
+
+		return nil
+	}
+}
+
+func setPdeathsig(attr *syscall.SysProcAttr, sig syscall.Signal) {
+	if sig != 0 {
+		attr.Pdeathsig = sig
+	}
+}
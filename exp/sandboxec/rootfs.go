@@ -0,0 +1,28 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+// Bind describes a host path made visible inside the root built by
+// [WithRootfs].
+type Bind struct {
+	// Source is the absolute host path to expose.
+	Source string
+
+	// Target is the absolute path inside the new root. Empty means Source.
+	Target string
+
+	// Writable mounts the path read-write instead of read-only.
+	Writable bool
+}
+
+// ReadOnlyBind exposes the host path at the same location, read-only.
+func ReadOnlyBind(path string) Bind {
+	return Bind{Source: path}
+}
+
+// ReadWriteBind exposes the host path at the same location, read-write.
+func ReadWriteBind(path string) Bind {
+	return Bind{Source: path, Writable: true}
+}
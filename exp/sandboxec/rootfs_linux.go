@@ -0,0 +1,477 @@
+// nolint
+//go:build linux
+// +build linux
+
+package sandboxec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"syscall"
+	"unsafe"
+
+	"github.com/landlock-lsm/go-landlock/landlock"
+	"go.dw1.io/x/exp/sandboxec/access"
+)
+
+// rootfsInitArg0 marks a re-executed binary as the rootfs init of a command.
+const rootfsInitArg0 = "sandboxec-rootfs-init"
+
+// rootfsInitFailure is the exit code of a rootfs init that failed before
+// executing the command.
+const rootfsInitFailure = 125
+
+const (
+	capSysAdmin          = 21
+	prCapAmbient         = 47
+	prCapAmbientClearAll = 4
+
+	linuxCapabilityVersion3 = 0x20080522
+
+	// statfs(2) flags that must be preserved when remounting a bind mount
+	// created from a more privileged mount namespace.
+	stNoSuid     = 0x2
+	stNoDev      = 0x4
+	stNoExec     = 0x8
+	stNoAtime    = 0x400
+	stNoDirAtime = 0x800
+	stRelAtime   = 0x1000
+)
+
+type rootfsConfig struct {
+	binds []Bind
+}
+
+// rootfsSpec is passed to the rootfs init on its command line.
+type rootfsSpec struct {
+	MountNS       string
+	Binds         []Bind
+	IgnoreMissing bool
+
+	ABI        int
+	BestEffort bool
+	Scoped     uint64
+	Seccomp    bool
+	FSRules    []rootfsFSRule
+	NetRules   []rootfsNetRule
+}
+
+type rootfsFSRule struct {
+	Path   string
+	Rights access.FS
+}
+
+type rootfsNetRule struct {
+	Port   uint16
+	Rights access.Network
+}
+
+// WithRootfs runs each command in a minimal root filesystem.
+//
+// Each command gets new user, mount and PID namespaces. Its root is an empty
+// read-only tmpfs holding only the listed binds, a fresh /proc, and a /dev
+// with null, zero, full, random, urandom, tty and a private shm. Paths
+// outside the binds do not exist inside the root. Binds are mounted in order;
+// read-only binds are not made read-only recursively. It requires
+// unprivileged user namespaces, and can be called more than once to add binds.
+//
+// Landlock forbids mount changes inside a domain, so with WithRootfs the
+// policy is not enforced on the current process. Each command re-executes
+// the current binary, builds the root, enforces the configured Landlock
+// policy on itself, and then executes the command. Landlock rules for paths
+// missing from the root are skipped. The returned Cmd's Path and Args name
+// that re-executed binary. A command that fails during setup exits with code
+// 125 and reports the cause on stderr.
+//
+// The command runs as PID 1 of its PID namespace. Like any init process, it
+// ignores signals it has no handler for, except SIGKILL, and when it exits,
+// the kernel kills the rest of the namespace.
+func WithRootfs(binds ...Bind) Option {
+	return func(cfg *config) error {
+		for _, bind := range binds {
+			if !filepath.IsAbs(bind.Source) {
+				return fmt.Errorf("%w: Rootfs bind source %q must be an absolute path", ErrInvalidOption, bind.Source)
+			}
+
+			if bind.Target != "" && !filepath.IsAbs(bind.Target) {
+				return fmt.Errorf("%w: Rootfs bind target %q must be an absolute path", ErrInvalidOption, bind.Target)
+			}
+		}
+
+		if cfg.rootfs == nil {
+			cfg.rootfs = &rootfsConfig{}
+		}
+
+		for _, bind := range binds {
+			bind.Source = filepath.Clean(bind.Source)
+			if bind.Target == "" {
+				bind.Target = bind.Source
+			}
+			bind.Target = filepath.Clean(bind.Target)
+
+			cfg.rootfs.binds = append(cfg.rootfs.binds, bind)
+		}
+
+		return nil
+	}
+}
+
+// wrap rewrites cmd to start through the rootfs init.
+func (r *rootfsConfig) wrap(cmd *exec.Cmd, c *config) {
+	if cmd.Err != nil {
+		return
+	}
+
+	mountNS, err := os.Readlink("/proc/self/ns/mnt")
+	if err != nil {
+		cmd.Err = fmt.Errorf("rootfs: read mount namespace: %w", err)
+
+		return
+	}
+
+	spec := rootfsSpec{
+		MountNS:       mountNS,
+		Binds:         r.binds,
+		IgnoreMissing: c.ignoreIfMissing,
+		ABI:           c.abi,
+		BestEffort:    c.bestEffort,
+		Scoped:        uint64(c.scoped),
+		Seccomp:       c.seccompNetFallback,
+	}
+
+	for _, rule := range c.fsRules {
+		spec.FSRules = append(spec.FSRules, rootfsFSRule{Path: rule.path, Rights: rule.rights})
+	}
+
+	for _, rule := range c.netRules {
+		spec.NetRules = append(spec.NetRules, rootfsNetRule{Port: rule.port, Rights: rule.rights})
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		cmd.Err = fmt.Errorf("rootfs: encode spec: %w", err)
+
+		return
+	}
+
+	cmd.Args = append([]string{rootfsInitArg0, string(data), cmd.Path}, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	uid, gid := os.Getuid(), os.Getgid()
+
+	attr := cmd.SysProcAttr
+	attr.Cloneflags |= syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+	attr.GidMappingsEnableSetgroups = false
+	attr.AmbientCaps = append(attr.AmbientCaps, capSysAdmin)
+}
+
+func init() {
+	if len(os.Args) < 4 || os.Args[0] != rootfsInitArg0 {
+		return
+	}
+
+	err := rootfsInit(os.Args[1], os.Args[2], os.Args[3:])
+	_, _ = fmt.Fprintf(os.Stderr, "sandboxec: rootfs: %v\n", err)
+	os.Exit(rootfsInitFailure)
+}
+
+// rootfsInit builds the root, enforces Landlock and executes path. It only
+// returns on failure.
+func rootfsInit(specJSON, path string, argv []string) error {
+	// Ambient capabilities are per thread; keep the one that clears them and
+	// calls execve the same.
+	goruntime.LockOSThread()
+
+	var spec rootfsSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		return fmt.Errorf("decode spec: %w", err)
+	}
+
+	// Refuse to touch the mounts of the parent's namespace, for example when
+	// the caller replaced Cmd SysProcAttr.
+	if mountNS, err := os.Readlink("/proc/self/ns/mnt"); err != nil || mountNS == spec.MountNS {
+		return errors.New("not running in a new mount namespace; was Cmd SysProcAttr replaced?")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "/"
+	}
+
+	if err := buildRootfs(spec.Binds, spec.IgnoreMissing); err != nil {
+		return err
+	}
+
+	if err := os.Chdir(cwd); err != nil {
+		_ = os.Chdir("/")
+	}
+
+	if _, err := spec.config().restrict(); err != nil {
+		return err
+	}
+
+	if err := dropCapabilities(); err != nil {
+		return err
+	}
+
+	if err := syscall.Exec(path, argv, os.Environ()); err != nil {
+		return fmt.Errorf("exec %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// dropCapabilities clears the ambient and inheritable capabilities raised for
+// the init, so a non-root command starts without them.
+func dropCapabilities() error {
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prCapAmbient, prCapAmbientClearAll, 0); errno != 0 {
+		return fmt.Errorf("clear ambient capabilities: %w", errno)
+	}
+
+	hdr := struct {
+		version uint32
+		pid     int32
+	}{version: linuxCapabilityVersion3}
+
+	var data [2]struct {
+		effective   uint32
+		permitted   uint32
+		inheritable uint32
+	}
+
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPGET, uintptr(unsafe.Pointer(&hdr)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return fmt.Errorf("get capabilities: %w", errno)
+	}
+
+	data[0].inheritable, data[1].inheritable = 0, 0
+
+	if _, _, errno := syscall.RawSyscall(syscall.SYS_CAPSET, uintptr(unsafe.Pointer(&hdr)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return fmt.Errorf("clear inheritable capabilities: %w", errno)
+	}
+
+	return nil
+}
+
+// config rebuilds the Landlock policy. Rule paths that are not inside the
+// root grant nothing and are skipped.
+func (spec rootfsSpec) config() *config {
+	c := &config{
+		abi:        spec.ABI,
+		bestEffort: spec.BestEffort,
+		scoped:     landlock.ScopedSet(spec.Scoped),
+
+		seccompNetFallback: spec.Seccomp,
+	}
+
+	for _, rule := range spec.FSRules {
+		if _, err := os.Stat(rule.Path); err != nil {
+			continue
+		}
+
+		c.fsRules = append(c.fsRules, fsRule{path: rule.Path, rights: rule.Rights})
+	}
+
+	for _, rule := range spec.NetRules {
+		c.netRules = append(c.netRules, netRule{port: rule.Port, rights: rule.Rights})
+	}
+
+	return c
+}
+
+// buildRootfs assembles the new root under a tmpfs and pivots into it.
+//
+// The layout follows bubblewrap: a scratch tmpfs becomes the root first, so
+// the old root stays reachable at /oldroot while /newroot is populated.
+func buildRootfs(binds []Bind, ignoreMissing bool) error {
+	const base = "/tmp"
+
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"make mounts private", func() error {
+			return syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, "")
+		}},
+		{"mount scratch tmpfs", func() error {
+			return syscall.Mount("tmpfs", base, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=0755")
+		}},
+		{"create scratch dirs", func() error {
+			if err := os.Mkdir(filepath.Join(base, "newroot"), 0o755); err != nil {
+				return err
+			}
+
+			return os.Mkdir(filepath.Join(base, "oldroot"), 0o755)
+		}},
+		{"pivot to scratch", func() error {
+			if err := syscall.PivotRoot(base, filepath.Join(base, "oldroot")); err != nil {
+				return err
+			}
+
+			return os.Chdir("/")
+		}},
+		{"mount root tmpfs", func() error {
+			return syscall.Mount("tmpfs", "/newroot", "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=0755")
+		}},
+		{"bind paths", func() error {
+			for _, bind := range binds {
+				err := bindMount("/oldroot"+bind.Source, "/newroot"+bind.Target, bind.Writable)
+				if err != nil && !(ignoreMissing && errors.Is(err, os.ErrNotExist)) {
+					return fmt.Errorf("bind %q: %w", bind.Source, err)
+				}
+			}
+
+			return nil
+		}},
+		{"mount /proc", func() error {
+			if err := os.MkdirAll("/newroot/proc", 0o755); err != nil {
+				return err
+			}
+
+			return syscall.Mount("proc", "/newroot/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+		}},
+		{"populate /dev", func() error {
+			return setupDev("/newroot/dev")
+		}},
+		{"pivot to new root", func() error {
+			if err := os.Chdir("/newroot"); err != nil {
+				return err
+			}
+
+			if err := syscall.PivotRoot(".", "."); err != nil {
+				return err
+			}
+
+			if err := syscall.Unmount(".", syscall.MNT_DETACH); err != nil {
+				return err
+			}
+
+			return os.Chdir("/")
+		}},
+		{"remount root read-only", func() error {
+			return syscall.Mount("", "/", "", syscall.MS_REMOUNT|syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, "")
+		}},
+	}
+
+	for _, step := range steps {
+		if err := step.fn(); err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+	}
+
+	return nil
+}
+
+// bindMount bind-mounts src onto dst, creating the mount point.
+func bindMount(src, dst string, writable bool) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if err := createMountPoint(dst, info.IsDir()); err != nil {
+		return err
+	}
+
+	if err := syscall.Mount(src, dst, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return err
+	}
+
+	if writable {
+		return nil
+	}
+
+	return remountReadOnly(dst)
+}
+
+// remountReadOnly makes the bind mount at path read-only. Flags locked by the
+// kernel for mounts from a more privileged namespace must be kept.
+func remountReadOnly(path string) error {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return err
+	}
+
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+	for _, f := range []struct {
+		st int64
+		ms uintptr
+	}{
+		{stNoSuid, syscall.MS_NOSUID},
+		{stNoDev, syscall.MS_NODEV},
+		{stNoExec, syscall.MS_NOEXEC},
+		{stNoAtime, syscall.MS_NOATIME},
+		{stNoDirAtime, syscall.MS_NODIRATIME},
+		{stRelAtime, syscall.MS_RELATIME},
+	} {
+		if int64(st.Flags)&f.st != 0 {
+			flags |= f.ms
+		}
+	}
+
+	return syscall.Mount("none", path, "", flags, "")
+}
+
+func createMountPoint(path string, dir bool) error {
+	if dir {
+		return os.MkdirAll(path, 0o755)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// setupDev mounts a tmpfs /dev with the basic device nodes bound from the
+// host, since user namespaces cannot create device nodes.
+func setupDev(dev string) error {
+	if err := os.MkdirAll(dev, 0o755); err != nil {
+		return err
+	}
+
+	if err := syscall.Mount("tmpfs", dev, "tmpfs", syscall.MS_NOSUID|syscall.MS_NOEXEC, "mode=0755"); err != nil {
+		return err
+	}
+
+	for _, name := range []string{"full", "null", "random", "tty", "urandom", "zero"} {
+		err := bindMount(filepath.Join("/oldroot/dev", name), filepath.Join(dev, name), true)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	for _, link := range [][2]string{
+		{"fd", "/proc/self/fd"},
+		{"stdin", "/proc/self/fd/0"},
+		{"stdout", "/proc/self/fd/1"},
+		{"stderr", "/proc/self/fd/2"},
+	} {
+		if err := os.Symlink(link[1], filepath.Join(dev, link[0])); err != nil {
+			return err
+		}
+	}
+
+	shm := filepath.Join(dev, "shm")
+	if err := os.Mkdir(shm, 0o1777); err != nil {
+		return err
+	}
+
+	return syscall.Mount("tmpfs", shm, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, "mode=1777")
+}
@@ -0,0 +1,213 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxOutput is the per-stream capture limit used by [Sandboxec.Run]
+// when RunSpec leaves MaxStdout or MaxStderr unset.
+const DefaultMaxOutput = 1 << 20
+
+// RunSpec describes a command executed by [Sandboxec.Run].
+type RunSpec struct {
+	// Name and Args are passed to CommandContext.
+	Name string
+	Args []string
+
+	// Dir, Env and Stdin are copied to the created Cmd.
+	Dir   string
+	Env   []string
+	Stdin io.Reader
+
+	// Stdout and Stderr, if non-nil, receive the full output streams as the
+	// command produces them, in addition to the bounded capture.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// MaxStdout and MaxStderr cap the captured bytes per stream. Zero or
+	// negative values use DefaultMaxOutput.
+	MaxStdout int64
+	MaxStderr int64
+}
+
+// Result describes a command executed by [Sandboxec.Run].
+type Result struct {
+	// Stdout and Stderr hold at most the configured limits of output.
+	Stdout []byte
+	Stderr []byte
+
+	// StdoutTruncated and StderrTruncated report whether output beyond the
+	// limit was discarded.
+	StdoutTruncated bool
+	StderrTruncated bool
+
+	// ExitCode is the command's exit code, or -1 if it did not exit normally.
+	ExitCode int
+
+	// Signal is the signal that terminated the command, if any.
+	Signal syscall.Signal
+
+	// WallTime is the elapsed time between Start and Wait returning.
+	WallTime time.Duration
+
+	// UserTime and SystemTime are the CPU times reported by the process state.
+	UserTime   time.Duration
+	SystemTime time.Duration
+
+	// SetupFailed reports whether the command never ran because sandbox
+	// setup failed, as reported by Cmd Err.
+	SetupFailed bool
+
+	// StartFailed reports whether the command itself could not be started,
+	// for example because the binary is missing or not executable.
+	StartFailed bool
+}
+
+// Run executes spec under the sandbox and captures bounded output.
+//
+// Unlike [exec.Cmd.Output] and [exec.Cmd.CombinedOutput], stdout and stderr
+// are capped, so untrusted commands cannot exhaust memory. The returned error
+// is the setup or wait error, if any; Result is non-nil in both cases.
+func (s *Sandboxec) Run(ctx context.Context, spec RunSpec) (*Result, error) {
+	res := &Result{ExitCode: -1}
+
+	cmd := s.CommandContext(ctx, spec.Name, spec.Args...)
+	if cmd.Err != nil {
+		res.SetupFailed = true
+
+		return res, cmd.Err
+	}
+
+	cmd.Dir = spec.Dir
+	cmd.Env = spec.Env
+	cmd.Stdin = spec.Stdin
+
+	stdout := NewLimitedBuffer(outputLimit(spec.MaxStdout))
+	stderr := NewLimitedBuffer(outputLimit(spec.MaxStderr))
+	cmd.Stdout = teeWriter(stdout, spec.Stdout)
+	cmd.Stderr = teeWriter(stderr, spec.Stderr)
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		res.StartFailed = true
+
+		return res, fmt.Errorf("start %q: %w", spec.Name, err)
+	}
+
+	err := cmd.Wait()
+	res.WallTime = time.Since(start)
+
+	res.Stdout = stdout.Bytes()
+	res.Stderr = stderr.Bytes()
+	res.StdoutTruncated = stdout.Truncated()
+	res.StderrTruncated = stderr.Truncated()
+
+	if state := cmd.ProcessState; state != nil {
+		res.ExitCode = state.ExitCode()
+		res.UserTime = state.UserTime()
+		res.SystemTime = state.SystemTime()
+
+		if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			res.Signal = status.Signal()
+		}
+	}
+
+	return res, err
+}
+
+// LimitedBuffer is an [io.Writer] that keeps at most a fixed number of bytes.
+//
+// Writes beyond the limit are discarded but still reported as successful, so
+// a command writing to it never sees a short write or a broken pipe. It is
+// safe for concurrent use.
+type LimitedBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+// NewLimitedBuffer returns a LimitedBuffer that keeps at most limit bytes.
+func NewLimitedBuffer(limit int64) *LimitedBuffer {
+	if limit < 0 {
+		limit = 0
+	}
+
+	return &LimitedBuffer{limit: limit}
+}
+
+// Write implements [io.Writer].
+func (b *LimitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := b.limit - int64(b.buf.Len())
+	if remaining <= 0 {
+		if len(p) > 0 {
+			b.truncated = true
+		}
+
+		return len(p), nil
+	}
+
+	if int64(len(p)) > remaining {
+		b.buf.Write(p[:remaining])
+		b.truncated = true
+
+		return len(p), nil
+	}
+
+	b.buf.Write(p)
+
+	return len(p), nil
+}
+
+// Bytes returns a copy of the retained bytes.
+func (b *LimitedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return bytes.Clone(b.buf.Bytes())
+}
+
+// Len returns the number of retained bytes.
+func (b *LimitedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Len()
+}
+
+// Truncated reports whether any written bytes were discarded.
+func (b *LimitedBuffer) Truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.truncated
+}
+
+func outputLimit(limit int64) int64 {
+	if limit <= 0 {
+		return DefaultMaxOutput
+	}
+
+	return limit
+}
+
+func teeWriter(capture *LimitedBuffer, stream io.Writer) io.Writer {
+	if stream == nil {
+		return capture
+	}
+
+	return io.MultiWriter(capture, stream)
+}
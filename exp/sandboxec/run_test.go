@@ -0,0 +1,51 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"go.dw1.io/x/exp/sandboxec/access"
+)
+
+func TestLimitedBuffer(t *testing.T) {
+	buf := NewLimitedBuffer(5)
+
+	if n, err := buf.Write([]byte("abc")); n != 3 || err != nil {
+		t.Fatalf("Write = (%d, %v), want (3, nil)", n, err)
+	}
+	if buf.Truncated() {
+		t.Fatalf("expected no truncation below the limit")
+	}
+
+	if n, err := buf.Write([]byte("defgh")); n != 5 || err != nil {
+		t.Fatalf("Write = (%d, %v), want (5, nil)", n, err)
+	}
+	if n, err := buf.Write([]byte("ij")); n != 2 || err != nil {
+		t.Fatalf("Write = (%d, %v), want (2, nil)", n, err)
+	}
+
+	if got := buf.Bytes(); !bytes.Equal(got, []byte("abcde")) {
+		t.Fatalf("Bytes = %q, want %q", got, "abcde")
+	}
+	if buf.Len() != 5 || !buf.Truncated() {
+		t.Fatalf("Len = %d, Truncated = %v, want 5, true", buf.Len(), buf.Truncated())
+	}
+}
+
+func TestRunSetupFailure(t *testing.T) {
+	sb := New(WithFSRule("", access.FS_READ))
+
+	res, err := sb.Run(context.Background(), RunSpec{Name: "/bin/true"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Fatalf("expected ErrInvalidOption, got %v", err)
+	}
+	if res == nil || !res.SetupFailed || res.StartFailed || res.ExitCode != -1 {
+		t.Fatalf("unexpected result for setup failure: %+v", res)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"os/exec"
 	"sync"
+	"time"
 )
 
 // Sandboxec configures Seatbelt restrictions for the current process and
@@ -20,11 +21,11 @@ type Sandboxec struct {
 	optErr    error
 	applyOnce sync.Once
 	applyErr  error
+	scopes    []ScopeStatus
+	netMode   NetworkMode
+	report    Report
 }
 
-// Cmd is an alias for [exec.Cmd] to preserve os/exec-style documentation links.
-type Cmd = exec.Cmd
-
 // New creates a Sandboxec configured by the provided options.
 //
 // Option errors are recorded and surfaced on the first call to Command or
@@ -57,26 +58,46 @@ func (s *Sandboxec) Command(name string, arg ...string) *Cmd {
 	s.enforceOnce()
 
 	cmd := exec.Command(name, arg...)
+	s.cfg.proc.apply(cmd, false)
 	if s.applyErr != nil {
 		cmd.Err = s.applyErr
 	}
 
-	return cmd
+	s.cfg.hooks.commandCreated(name, arg, cmd.Err)
+
+	return newCmd(context.Background(), cmd, s.cfg.hooks)
 }
 
 // CommandContext returns a Cmd configured like [exec.CommandContext], after
 // enforcing Seatbelt for the current process.
 //
 // If enforcement fails, the returned Cmd has Err set to that failure.
+//
+// When [WithKillProcessTree] is set, canceling ctx signals the command's whole
+// process group instead of only the direct child.
 func (s *Sandboxec) CommandContext(ctx context.Context, name string, arg ...string) *Cmd {
 	s.enforceOnce()
 
 	cmd := exec.CommandContext(ctx, name, arg...)
+	s.cfg.proc.apply(cmd, true)
 	if s.applyErr != nil {
 		cmd.Err = s.applyErr
 	}
 
-	return cmd
+	s.cfg.hooks.commandCreated(name, arg, cmd.Err)
+
+	return newCmd(ctx, cmd, s.cfg.hooks)
+}
+
+// Enforce applies Seatbelt restrictions to the current process without
+// creating a command.
+//
+// It returns the same error that Command and CommandContext surface through
+// Cmd Err. Calling it more than once is safe.
+func (s *Sandboxec) Enforce() error {
+	s.enforceOnce()
+
+	return s.applyErr
 }
 
 // LookPath returns the path to an executable like [exec.LookPath].
@@ -85,9 +106,17 @@ func LookPath(file string) (string, error) {
 }
 
 func (s *Sandboxec) enforceOnce() {
+	enforced := false
 	s.applyOnce.Do(func() {
 		s.applyErr = s.enforce()
+		s.completeReport()
+		enforced = true
 	})
+
+	// Run the hooks outside of the Once, so they may create commands.
+	if enforced {
+		s.cfg.hooks.enforced(s.report)
+	}
 }
 
 func (s *Sandboxec) enforce() error {
@@ -95,15 +124,41 @@ func (s *Sandboxec) enforce() error {
 		return s.optErr
 	}
 
+	if s.cfg.unsafeHostRuntime {
+		start, before := time.Now(), len(s.cfg.fsRules)
+
+		if err := s.cfg.resolveHostRuntime(); err != nil {
+			return err
+		}
+
+		s.report.RuntimeDiscovery = time.Since(start)
+		s.report.HostRuntimeRules = len(s.cfg.fsRules) - before
+	}
+
 	policy := s.cfg.seatbeltPolicy()
 
 	if err := applySeatbelt(policy, s.cfg.flags); err != nil {
 		if s.cfg.bestEffort {
+			s.report.Downgraded = true
+
 			return nil
 		}
 
 		return err
 	}
 
+	s.netMode = NetworkSeatbelt
+
 	return nil
 }
+
+// completeReport fills the enforcement report from the applied config.
+func (s *Sandboxec) completeReport() {
+	r := &s.report
+	r.Backend = "seatbelt"
+	r.BestEffort = s.cfg.bestEffort
+	r.FSRules = len(s.cfg.fsRules)
+	r.NetRules = len(s.cfg.netRules)
+	r.Network = s.netMode
+	r.Err = s.applyErr
+}
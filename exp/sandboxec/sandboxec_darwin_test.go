@@ -54,6 +54,10 @@ func TestDarwinUnsupportedOptions(t *testing.T) {
 		{name: "WithABI", opt: WithABI(6)},
 		{name: "WithIgnoreIfMissing", opt: WithIgnoreIfMissing()},
 		{name: "WithRestrictScoped", opt: WithRestrictScoped()},
+		{name: "WithScopeAbstractUnixSocket", opt: WithScopeAbstractUnixSocket()},
+		{name: "WithScopeSignal", opt: WithScopeSignal()},
+		{name: "WithRootfs", opt: WithRootfs(ReadOnlyBind("/usr"))},
+		{name: "WithSeccompNetworkFallback", opt: WithSeccompNetworkFallback()},
 	}
 
 	for _, tt := range tests {
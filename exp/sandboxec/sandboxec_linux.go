@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"sync"
+	"time"
 
 	"github.com/landlock-lsm/go-landlock/landlock"
 	"github.com/landlock-lsm/go-landlock/landlock/syscall"
@@ -26,11 +27,11 @@ type Sandboxec struct {
 	optErr    error
 	applyOnce sync.Once
 	applyErr  error
+	scopes    []ScopeStatus
+	netMode   NetworkMode
+	report    Report
 }
 
-// Cmd is an alias for [exec.Cmd] to preserve os/exec-style documentation links.
-type Cmd = exec.Cmd
-
 // New creates a Sandboxec configured by the provided options.
 //
 // Option errors are recorded and surfaced on the first call to Command or
@@ -63,26 +64,51 @@ func (s *Sandboxec) Command(name string, arg ...string) *Cmd {
 	s.enforceOnce()
 
 	cmd := exec.Command(name, arg...)
+	s.cfg.proc.apply(cmd, false)
 	if s.applyErr != nil {
 		cmd.Err = s.applyErr
+	} else if s.cfg.rootfs != nil {
+		s.cfg.rootfs.wrap(cmd, &s.cfg)
 	}
 
-	return cmd
+	s.cfg.hooks.commandCreated(name, arg, cmd.Err)
+
+	return newCmd(context.Background(), cmd, s.cfg.hooks)
 }
 
 // CommandContext returns a Cmd configured like [exec.CommandContext], after
 // enforcing Landlock for the current process.
 //
 // If enforcement fails, the returned Cmd has Err set to that failure.
+//
+// When [WithKillProcessTree] is set, canceling ctx signals the command's whole
+// process group instead of only the direct child.
 func (s *Sandboxec) CommandContext(ctx context.Context, name string, arg ...string) *Cmd {
 	s.enforceOnce()
 
 	cmd := exec.CommandContext(ctx, name, arg...)
+	s.cfg.proc.apply(cmd, true)
 	if s.applyErr != nil {
 		cmd.Err = s.applyErr
+	} else if s.cfg.rootfs != nil {
+		s.cfg.rootfs.wrap(cmd, &s.cfg)
 	}
 
-	return cmd
+	s.cfg.hooks.commandCreated(name, arg, cmd.Err)
+
+	return newCmd(ctx, cmd, s.cfg.hooks)
+}
+
+// Enforce applies Landlock restrictions to the current process without
+// creating a command.
+//
+// It returns the same error that Command and CommandContext surface through
+// Cmd Err. Calling it more than once is safe. With [WithRootfs], it only
+// prepares the policy that each command enforces on itself.
+func (s *Sandboxec) Enforce() error {
+	s.enforceOnce()
+
+	return s.applyErr
 }
 
 // LookPath returns the path to an executable like [exec.LookPath].
@@ -91,9 +117,17 @@ func LookPath(file string) (string, error) {
 }
 
 func (s *Sandboxec) enforceOnce() {
+	enforced := false
 	s.applyOnce.Do(func() {
 		s.applyErr = s.enforce()
+		s.completeReport()
+		enforced = true
 	})
+
+	// Run the hooks outside of the Once, so they may create commands.
+	if enforced {
+		s.cfg.hooks.enforced(s.report)
+	}
 }
 
 func (s *Sandboxec) enforce() error {
@@ -101,23 +135,113 @@ func (s *Sandboxec) enforce() error {
 		return s.optErr
 	}
 
-	cfg, err := toLandlockConfig(s.cfg.abi)
-	if err != nil {
+	if _, err := toLandlockConfig(s.cfg.abi); err != nil {
 		return err
 	}
 
-	if s.cfg.bestEffort {
-		cfg = cfg.BestEffort()
+	if err := s.cfg.validateCompatibility(); err != nil {
+		return err
 	}
 
-	if err := s.cfg.validateCompatibility(); err != nil {
+	if s.cfg.unsafeHostRuntime {
+		start, before := time.Now(), len(s.cfg.fsRules)
+
+		if err := s.cfg.resolveHostRuntime(); err != nil {
+			return err
+		}
+
+		s.report.RuntimeDiscovery = time.Since(start)
+		s.report.HostRuntimeRules = len(s.cfg.fsRules) - before
+	}
+
+	// Landlock forbids mount topology changes inside a domain, so with a
+	// rootfs the policy is applied by each command's init after the view is
+	// built instead of on the current process.
+	s.netMode = s.cfg.networkMode()
+
+	if s.cfg.rootfs == nil {
+		mode, err := s.cfg.restrict()
+		if err != nil {
+			s.netMode = NetworkUnrestricted
+
+			return err
+		}
+
+		s.netMode = mode
+	}
+
+	if s.cfg.scoped != 0 {
+		s.scopes = s.cfg.scopeStatus()
+	}
+
+	return nil
+}
+
+// restrict applies the configured Landlock policy, and the seccomp network
+// fallback when requested, to the current process. It returns the network
+// mode actually in effect.
+func (c *config) restrict() (NetworkMode, error) {
+	if err := c.restrictLandlock(); err != nil {
+		return NetworkUnrestricted, err
+	}
+
+	mode := c.networkMode()
+	if mode != NetworkSeccompDeny {
+		return mode, nil
+	}
+
+	if err := installSeccompNetworkDeny(); err != nil {
+		if c.bestEffort {
+			return NetworkUnrestricted, nil
+		}
+
+		return NetworkUnrestricted, err
+	}
+
+	return mode, nil
+}
+
+// networkMode predicts the network restriction for the effective ABI.
+func (c *config) networkMode() NetworkMode {
+	switch {
+	case c.effectiveABI() >= 4:
+		return NetworkLandlock
+	case c.seccompNetFallback && len(c.netRules) == 0:
+		return NetworkSeccompDeny
+	default:
+		return NetworkUnrestricted
+	}
+}
+
+// effectiveABI returns the ABI Landlock actually enforces. In best-effort
+// mode, go-landlock downgrades to what the kernel supports.
+func (c *config) effectiveABI() int {
+	if !c.bestEffort {
+		return c.abi
+	}
+
+	supported, err := getLandlockABIVersion()
+	if err != nil {
+		return 0
+	}
+
+	return min(c.abi, supported)
+}
+
+func (c *config) restrictLandlock() error {
+	cfg, err := toLandlockConfig(c.abi)
+	if err != nil {
 		return err
 	}
 
-	hasFSRules := len(s.cfg.fsRules) > 0
-	hasNetRules := len(s.cfg.netRules) > 0
+	if c.bestEffort {
+		cfg = cfg.BestEffort()
+	}
+
+	hasFSRules := len(c.fsRules) > 0
+	hasNetRules := len(c.netRules) > 0
 
-	if !hasFSRules && !hasNetRules && !s.cfg.restrictScoped {
+	if !hasFSRules && !hasNetRules && c.scoped == 0 {
 		if err := cfg.Restrict(); err != nil {
 			return fmt.Errorf("landlock default restrict failed: %w", err)
 		}
@@ -126,7 +250,7 @@ func (s *Sandboxec) enforce() error {
 	}
 
 	if hasFSRules {
-		rules, err := s.buildFSRules()
+		rules, err := c.buildFSRules()
 		if err != nil {
 			return err
 		}
@@ -140,11 +264,11 @@ func (s *Sandboxec) enforce() error {
 
 	// Landlock ABI V1-V3 do not support network rules, so only attempt to apply
 	// them if the configured ABI is V4+.
-	if s.cfg.abi >= 4 {
+	if c.abi >= 4 {
 		var rules []landlock.Rule
 
 		if hasNetRules {
-			for _, rule := range s.cfg.netRules {
+			for _, rule := range c.netRules {
 				if rule.rights&access.NETWORK_BIND_TCP != 0 {
 					rules = append(rules, landlock.BindTCP(rule.port))
 				}
@@ -160,8 +284,13 @@ func (s *Sandboxec) enforce() error {
 		}
 	}
 
-	if s.cfg.restrictScoped {
-		if err := cfg.RestrictScoped(); err != nil {
+	if c.scoped != 0 {
+		scopedCfg := landlock.MustConfig(c.scoped)
+		if c.bestEffort {
+			scopedCfg = scopedCfg.BestEffort()
+		}
+
+		if err := scopedCfg.RestrictScoped(); err != nil {
 			return fmt.Errorf("landlock scoped restrict failed: %w", err)
 		}
 	}
@@ -169,13 +298,56 @@ func (s *Sandboxec) enforce() error {
 	return nil
 }
 
-func (s *Sandboxec) buildFSRules() ([]landlock.Rule, error) {
+// completeReport fills the enforcement report from the applied config.
+func (s *Sandboxec) completeReport() {
+	r := &s.report
+	r.Backend = "landlock"
+	r.ABI = s.cfg.abi
+	r.BestEffort = s.cfg.bestEffort
+	r.FSRules = len(s.cfg.fsRules)
+	r.NetRules = len(s.cfg.netRules)
+	r.Scopes = s.scopes
+	r.Network = s.netMode
+	r.PerCommand = s.cfg.rootfs != nil
+	r.Err = s.applyErr
+
+	if s.applyErr == nil {
+		r.EffectiveABI = s.cfg.effectiveABI()
+		r.Downgraded = r.EffectiveABI < r.ABI
+	}
+}
+
+// scopeStatus reports each requested scope and whether the running kernel
+// enforces it. In best-effort mode, go-landlock silently drops scopes the
+// kernel does not support.
+func (c *config) scopeStatus() []ScopeStatus {
+	supported, err := getLandlockABIVersion()
+	if err != nil {
+		supported = 0
+	}
+
+	var statuses []ScopeStatus
+	for _, scope := range scopeTable {
+		if c.scoped&scope.set == 0 {
+			continue
+		}
+
+		statuses = append(statuses, ScopeStatus{
+			Name:     scope.name,
+			Enforced: supported >= scope.abi,
+		})
+	}
+
+	return statuses
+}
+
+func (c *config) buildFSRules() ([]landlock.Rule, error) {
 	var rules []landlock.Rule
 
-	for _, rule := range s.cfg.fsRules {
+	for _, rule := range c.fsRules {
 		info, err := os.Stat(rule.path)
 		if err != nil {
-			if os.IsNotExist(err) && s.cfg.ignoreIfMissing {
+			if os.IsNotExist(err) && c.ignoreIfMissing {
 				fileAccess := filterAccess(rule.rights, false)
 				dirAccess := filterAccess(rule.rights, true)
 
@@ -197,7 +369,7 @@ func (s *Sandboxec) buildFSRules() ([]landlock.Rule, error) {
 
 		fsRule := landlock.PathAccess(filterAccess(rule.rights, info.IsDir()), rule.path)
 
-		if s.cfg.ignoreIfMissing {
+		if c.ignoreIfMissing {
 			fsRule = fsRule.IgnoreIfMissing()
 		}
 
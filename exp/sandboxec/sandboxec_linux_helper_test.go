@@ -5,11 +5,13 @@
 package sandboxec
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"os"
@@ -70,6 +72,20 @@ func TestHelperProcess(t *testing.T) {
 		err = helperCurlDenied()
 	case "curl-no-net-rules-denied":
 		err = helperCurlNoNetworkRulesDenied()
+	case "kill-tree":
+		err = helperKillProcessTree()
+	case "run-bounded-output":
+		err = helperRunBoundedOutput()
+	case "rootfs":
+		err = helperRootfs()
+	case "seccomp-net-fallback":
+		err = helperSeccompNetFallback()
+	case "hooks":
+		err = helperHooks()
+	case "scope-signal":
+		err = helperScope(WithScopeSignal(), true, false)
+	case "scope-abstract-unix-socket":
+		err = helperScope(WithScopeAbstractUnixSocket(), false, true)
 	default:
 		fmt.Fprintf(os.Stderr, "unknown scenario: %s\n", scenario)
 		os.Exit(2)
@@ -734,6 +750,116 @@ func helperCurlNoNetworkRulesDenied() error {
 	return fmt.Errorf("unexpected curl denial mode: %v: %s", err, strings.TrimSpace(string(output)))
 }
 
+func helperKillProcessTree() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sb := newSandboxWithBaseExec(
+		WithBestEffort(),
+		WithFSRule("/", access.FS_READ_EXEC),
+		WithKillProcessTree(100*time.Millisecond),
+	)
+	cmd := sb.CommandContext(ctx, "/bin/sh", "-c", `/bin/sh -c 'trap "" TERM; exec /bin/sleep 30' & echo $!; wait`)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("read grandchild pid: %w", err)
+	}
+	grandchild, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return fmt.Errorf("parse grandchild pid: %w", err)
+	}
+
+	cancel()
+	_ = cmd.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if !processAlive(grandchild) {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	_ = syscall.Kill(grandchild, syscall.SIGKILL)
+
+	return fmt.Errorf("grandchild %d survived context cancellation", grandchild)
+}
+
+func helperRunBoundedOutput() error {
+	sb := newSandboxWithBaseExec(WithBestEffort(), WithFSRule("/", access.FS_READ_EXEC))
+
+	var streamed bytes.Buffer
+	res, err := sb.Run(context.Background(), RunSpec{
+		Name:      "/bin/sh",
+		Args:      []string{"-c", "head -c 65536 /dev/zero; echo oops >&2; exit 3"},
+		Stdout:    &streamed,
+		MaxStdout: 1024,
+	})
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("expected exit error, got %v", err)
+	}
+	if res.SetupFailed || res.StartFailed {
+		return fmt.Errorf("expected command failure, got setup or start failure")
+	}
+	if res.ExitCode != 3 || res.Signal != 0 {
+		return fmt.Errorf("unexpected exit status: code=%d signal=%v", res.ExitCode, res.Signal)
+	}
+	if len(res.Stdout) != 1024 || !res.StdoutTruncated {
+		return fmt.Errorf("unexpected stdout capture: len=%d truncated=%v", len(res.Stdout), res.StdoutTruncated)
+	}
+	if streamed.Len() != 65536 {
+		return fmt.Errorf("unexpected streamed stdout length: %d", streamed.Len())
+	}
+	if string(res.Stderr) != "oops\n" || res.StderrTruncated {
+		return fmt.Errorf("unexpected stderr capture: %q truncated=%v", res.Stderr, res.StderrTruncated)
+	}
+	if res.WallTime <= 0 {
+		return fmt.Errorf("expected positive wall time, got %v", res.WallTime)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	res, err = sb.Run(ctx, RunSpec{Name: "/bin/sleep", Args: []string{"5"}})
+	if err == nil {
+		return fmt.Errorf("expected killed command to fail")
+	}
+	if res.SetupFailed || res.Signal != syscall.SIGKILL || res.ExitCode != -1 {
+		return fmt.Errorf("unexpected result for killed command: %+v", res)
+	}
+
+	res, err = sb.Run(context.Background(), RunSpec{Name: "/nonexistent/sandboxec-tool"})
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("expected ErrNotExist for a missing binary, got %v", err)
+	}
+	if res.SetupFailed || !res.StartFailed || res.ExitCode != -1 {
+		return fmt.Errorf("unexpected result for missing binary: %+v", res)
+	}
+
+	return nil
+}
+
+func processAlive(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+
+	// A zombie waiting to be reaped by init is already dead.
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
 func runWithTimeout(useSandbox bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
 	defer cancel()
@@ -802,6 +928,234 @@ func isPermissionDenied(err error) bool {
 	return strings.Contains(strings.ToLower(err.Error()), "permission denied")
 }
 
+func helperRootfs() error {
+	dir, err := os.MkdirTemp("", "sandboxec-rootfs-")
+	if err != nil {
+		return fmt.Errorf("mkdir temp: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	sb := New(
+		WithIgnoreIfMissing(),
+		WithRootfs(
+			ReadOnlyBind("/bin"),
+			ReadOnlyBind("/usr"),
+			ReadOnlyBind("/lib"),
+			ReadOnlyBind("/lib64"),
+			ReadWriteBind(dir),
+			Bind{Source: dir, Target: "/work/ro"},
+		),
+		WithFSRule("/", access.FS_READ_WRITE_EXEC),
+	)
+
+	script := `echo $$ > out &&
+		test ! -e /etc/passwd &&
+		test -e /proc/self/status &&
+		test -c /dev/null &&
+		test -e /work/ro/out &&
+		! touch /usr/sandboxec-rootfs 2>/dev/null &&
+		! touch /work/ro/denied 2>/dev/null`
+
+	cmd := sb.Command("/bin/sh", "-c", script)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(out), "sandboxec: rootfs:") {
+			return fmt.Errorf("SKIP: rootfs unavailable: %s", strings.TrimSpace(string(out)))
+		}
+
+		return fmt.Errorf("rootfs command failed: %v\n%s", err, out)
+	}
+
+	pid, err := os.ReadFile(filepath.Join(dir, "out"))
+	if err != nil {
+		return fmt.Errorf("read command output through writable bind: %w", err)
+	}
+	if strings.TrimSpace(string(pid)) != "1" {
+		return fmt.Errorf("expected the command to be PID 1, got %q", pid)
+	}
+
+	if _, err := os.ReadFile("/etc/hosts"); err != nil {
+		return fmt.Errorf("expected the current process to stay unrestricted: %w", err)
+	}
+
+	return nil
+}
+
+func helperSeccompNetFallback() error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("SKIP: tcp listen failed: %v", err)
+	}
+	defer func() {
+		_ = ln.Close()
+	}()
+
+	sb := New(WithABI(3), WithSeccompNetworkFallback(), WithFSRule("/", access.FS_READ_EXEC))
+	if err := sb.Enforce(); err != nil {
+		if isLandlockSkip(err) {
+			return fmt.Errorf("SKIP: %v", err)
+		}
+
+		return fmt.Errorf("enforce: %w", err)
+	}
+
+	if mode := sb.NetworkMode(); mode != NetworkSeccompDeny {
+		return fmt.Errorf("network mode = %v, want %v", mode, NetworkSeccompDeny)
+	}
+
+	if conn, err := net.Dial("tcp", ln.Addr().String()); !errors.Is(err, syscall.EACCES) {
+		if conn != nil {
+			_ = conn.Close()
+		}
+
+		return fmt.Errorf("expected tcp dial to fail with EACCES, got %v", err)
+	}
+
+	if _, err := net.Dial("udp6", "[::1]:53"); !errors.Is(err, syscall.EACCES) {
+		return fmt.Errorf("expected udp6 dial to fail with EACCES, got %v", err)
+	}
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		return fmt.Errorf("expected unix sockets to stay allowed: %w", err)
+	}
+	_ = syscall.Close(fds[0])
+	_ = syscall.Close(fds[1])
+
+	// The filter is inherited by commands.
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		return nil
+	}
+
+	out, err := sb.Command(bash, "-c", "exec 3<>/dev/tcp/"+strings.Replace(ln.Addr().String(), ":", "/", 1)).CombinedOutput()
+	if err == nil || !bytes.Contains(out, []byte("Permission denied")) {
+		return fmt.Errorf("expected child tcp connect to be denied, got %v: %s", err, out)
+	}
+
+	return nil
+}
+
+func helperHooks() error {
+	var logs bytes.Buffer
+	var report Report
+	var events []string
+
+	sb := newSandboxWithBaseExec(
+		WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+		OnEnforce(func(r Report) {
+			report = r
+			events = append(events, "enforce")
+		}),
+		OnCommandStart(func(cmd *Cmd) {
+			events = append(events, "start:"+filepath.Base(cmd.Path))
+		}),
+		OnCommandExit(func(cmd *Cmd, err error) {
+			events = append(events, fmt.Sprintf("exit:%v", err))
+		}),
+	)
+
+	res, err := sb.Run(context.Background(), RunSpec{Name: "/bin/sh", Args: []string{"-c", "exit 4"}})
+	if res == nil || res.ExitCode != 4 {
+		return fmt.Errorf("unexpected run result: %+v, %v", res, err)
+	}
+
+	if report.Err != nil {
+		if isLandlockSkip(report.Err) {
+			return fmt.Errorf("SKIP: %v", report.Err)
+		}
+
+		return fmt.Errorf("enforce: %w", report.Err)
+	}
+
+	// Commands started by the caller are reported too, and only once.
+	cmd := sb.Command("/bin/sh", "-c", "exit 5")
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	_ = cmd.Wait()
+	_ = cmd.Wait()
+
+	out, err := sb.Command("/bin/sh", "-c", "echo out; echo err >&2; exit 6").Output()
+	var exitErr *exec.ExitError
+	if string(out) != "out\n" || !errors.As(err, &exitErr) || string(exitErr.Stderr) != "err\n" {
+		return fmt.Errorf("unexpected Output result: %q, %v", out, err)
+	}
+
+	want := []string{"enforce", "start:sh", "exit:exit status 4", "start:sh", "exit:exit status 5", "start:sh", "exit:exit status 6"}
+	if strings.Join(events, ",") != strings.Join(want, ",") {
+		return fmt.Errorf("events = %q, want %q", events, want)
+	}
+
+	if report.Backend != "landlock" || report.FSRules != 7 || report.ABI == 0 || report.EffectiveABI != report.ABI || report.Downgraded {
+		return fmt.Errorf("unexpected report: %+v", report)
+	}
+
+	for _, want := range []string{`"msg":"sandboxec: enforced"`, `"fs_rules":7`, `"msg":"sandboxec: command started"`, `"msg":"sandboxec: command exited"`, `"exit_code":4`, `"exit_code":5`} {
+		if !strings.Contains(logs.String(), want) {
+			return fmt.Errorf("logs missing %s:\n%s", want, logs.String())
+		}
+	}
+
+	return nil
+}
+
+// helperScope enforces a single scope and checks signaling the test process
+// (outside the domain) and connecting to an abstract socket created before
+// enforcement.
+func helperScope(opt Option, wantSignalDenied, wantSocketDenied bool) error {
+	name := fmt.Sprintf("@sandboxec-scope-%d", os.Getpid())
+	ln, err := net.Listen("unix", name)
+	if err != nil {
+		return fmt.Errorf("SKIP: abstract socket listen failed: %v", err)
+	}
+	defer func() {
+		_ = ln.Close()
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	sb := New(WithABI(6), opt)
+	if err := sb.Enforce(); err != nil {
+		if isLandlockSkip(err) {
+			return fmt.Errorf("SKIP: %v", err)
+		}
+
+		return fmt.Errorf("enforce: %w", err)
+	}
+
+	scopes := sb.Scopes()
+	if len(scopes) != 1 || !scopes[0].Enforced {
+		return fmt.Errorf("unexpected scope status: %+v", scopes)
+	}
+
+	err = syscall.Kill(os.Getppid(), 0)
+	if signalDenied := errors.Is(err, syscall.EPERM); signalDenied != wantSignalDenied {
+		return fmt.Errorf("signal parent: denied=%v, want %v (err=%v)", signalDenied, wantSignalDenied, err)
+	}
+
+	conn, err := net.Dial("unix", name)
+	if err == nil {
+		_ = conn.Close()
+	}
+	if socketDenied := errors.Is(err, syscall.EPERM); socketDenied != wantSocketDenied {
+		return fmt.Errorf("connect abstract socket: denied=%v, want %v (err=%v)", socketDenied, wantSocketDenied, err)
+	}
+
+	return nil
+}
+
 func isLandlockSkip(err error) bool {
 	return errors.Is(err, ErrLandlockUnavailable) || errors.Is(err, ErrABINotSupported)
 }
@@ -113,3 +113,31 @@ func TestCurlDenied(t *testing.T) {
 func TestCurlNoNetworkRulesDenied(t *testing.T) {
 	runHelper(t, "curl-no-net-rules-denied", nil)
 }
+
+func TestKillProcessTree(t *testing.T) {
+	runHelper(t, "kill-tree", nil)
+}
+
+func TestRunBoundedOutput(t *testing.T) {
+	runHelper(t, "run-bounded-output", nil)
+}
+
+func TestScopeSignalOnly(t *testing.T) {
+	runHelper(t, "scope-signal", nil)
+}
+
+func TestScopeAbstractUnixSocketOnly(t *testing.T) {
+	runHelper(t, "scope-abstract-unix-socket", nil)
+}
+
+func TestRootfs(t *testing.T) {
+	runHelper(t, "rootfs", nil)
+}
+
+func TestSeccompNetworkFallback(t *testing.T) {
+	runHelper(t, "seccomp-net-fallback", nil)
+}
+
+func TestHooks(t *testing.T) {
+	runHelper(t, "hooks", nil)
+}
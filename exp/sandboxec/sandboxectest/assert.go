@@ -0,0 +1,154 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxectest
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// DialTimeout bounds the connection attempts made by AssertConnectAllowed
+// and AssertConnectDenied.
+var DialTimeout = 2 * time.Second
+
+// AssertReadable fails t unless path can be opened for reading.
+func AssertReadable(t testing.TB, path string) {
+	t.Helper()
+
+	if err := tryRead(path); err != nil {
+		t.Errorf("expected %q to be readable: %v", path, err)
+	}
+}
+
+// AssertNotReadable fails t unless opening path for reading is denied.
+func AssertNotReadable(t testing.TB, path string) {
+	t.Helper()
+
+	err := tryRead(path)
+	if err == nil {
+		t.Errorf("expected %q to be unreadable, but it was read", path)
+	} else if !IsDenied(err) {
+		t.Errorf("expected %q to be denied for reading, got %v", path, err)
+	}
+}
+
+// AssertWritable fails t unless path can be written.
+//
+// For a directory, a temporary file is created and removed inside it. For a
+// file, it is opened for appending without modifying its contents.
+func AssertWritable(t testing.TB, path string) {
+	t.Helper()
+
+	if err := tryWrite(path); err != nil {
+		t.Errorf("expected %q to be writable: %v", path, err)
+	}
+}
+
+// AssertNotWritable fails t unless writing to path is denied.
+func AssertNotWritable(t testing.TB, path string) {
+	t.Helper()
+
+	err := tryWrite(path)
+	if err == nil {
+		t.Errorf("expected %q to be unwritable, but it was written", path)
+	} else if !IsDenied(err) {
+		t.Errorf("expected %q to be denied for writing, got %v", path, err)
+	}
+}
+
+// AssertConnectAllowed fails t if the sandbox denies a TCP connection to
+// addr.
+//
+// A refused or timed-out connection still counts as allowed, since the
+// sandbox let the attempt through.
+func AssertConnectAllowed(t testing.TB, addr string) {
+	t.Helper()
+
+	if err := tryConnect(addr); err != nil && IsDenied(err) {
+		t.Errorf("expected connect to %s to be allowed: %v", addr, err)
+	}
+}
+
+// AssertConnectDenied fails t unless the sandbox denies a TCP connection to
+// addr.
+func AssertConnectDenied(t testing.TB, addr string) {
+	t.Helper()
+
+	err := tryConnect(addr)
+	if err == nil {
+		t.Errorf("expected connect to %s to be denied, but it succeeded", addr)
+	} else if !IsDenied(err) {
+		t.Errorf("expected connect to %s to be denied, got %v", addr, err)
+	}
+}
+
+// IsDenied reports whether err is a permission failure caused by the sandbox.
+func IsDenied(err error) bool {
+	return errors.Is(err, os.ErrPermission) ||
+		errors.Is(err, syscall.EACCES) ||
+		errors.Is(err, syscall.EPERM)
+}
+
+func tryRead(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		_, err = f.Readdirnames(1)
+	} else {
+		_, err = f.Read(make([]byte, 1))
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+func tryWrite(path string) error {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		f, err := os.CreateTemp(path, ".sandboxectest-*")
+		if err != nil {
+			return err
+		}
+
+		_ = f.Close()
+
+		return os.Remove(f.Name())
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func tryConnect(addr string) error {
+	conn, err := net.DialTimeout("tcp", addr, DialTimeout)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
@@ -0,0 +1,23 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+// Package sandboxectest provides helpers for testing code that enforces
+// sandboxec policies.
+//
+// Sandbox enforcement cannot be undone for the lifetime of a process, so each
+// sandboxed test body runs in a re-executed copy of the test binary. Run
+// handles the re-execution, forwards the child's output to the parent test,
+// and skips cleanly when the sandbox backend is unavailable.
+//
+// Example:
+//
+//	func TestPolicy(t *testing.T) {
+//		sandboxectest.Run(t, []sandboxec.Option{
+//			sandboxec.WithFSRule("/etc", access.FS_READ),
+//		}, func(t *testing.T) {
+//			sandboxectest.AssertReadable(t, "/etc/hosts")
+//			sandboxectest.AssertNotWritable(t, "/etc/hosts")
+//		})
+//	}
+package sandboxectest
@@ -0,0 +1,204 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxectest
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.dw1.io/x/exp/sandboxec"
+)
+
+const (
+	envRun     = "SANDBOXECTEST_RUN"
+	envTempDir = "SANDBOXECTEST_TEMPDIR_"
+	skipMarker = "sandboxectest: SKIP: "
+	doneMarker = "sandboxectest: DONE: "
+)
+
+var (
+	runCountsMu sync.Mutex
+	runCounts   = make(map[string]int)
+
+	tempDirsMu sync.Mutex
+	tempDirs   = make(map[string][]string)
+)
+
+// Run executes body in a re-executed helper process after enforcing a
+// sandbox built from opts.
+//
+// The parent test waits for the helper, fails with the helper's output if
+// the body fails, and skips if the sandbox backend is unavailable on the
+// host. Code in the test outside body runs in both processes, so keep setup
+// that must happen once in the parent inside a guard or before t.Run.
+//
+// A test may call Run more than once; each call gets its own helper process.
+func Run(t *testing.T, opts []sandboxec.Option, body func(t *testing.T)) {
+	t.Helper()
+
+	id := nextRunID(t.Name())
+
+	if target, ok := os.LookupEnv(envRun); ok {
+		if target == id {
+			runChild(t, id, opts, body)
+		}
+
+		return
+	}
+
+	runParent(t, id)
+}
+
+// TempDir returns a temporary directory shared by the parent test and the
+// helper processes started by Run.
+//
+// In the parent it behaves like t.TempDir and records the path for helpers.
+// In a helper it returns the path created by the parent. Call it before Run,
+// in the same order in both processes.
+func TempDir(t *testing.T) string {
+	t.Helper()
+
+	tempDirsMu.Lock()
+	defer tempDirsMu.Unlock()
+
+	name := t.Name()
+	if _, ok := tempDirs[name]; !ok {
+		t.Cleanup(func() {
+			tempDirsMu.Lock()
+			delete(tempDirs, name)
+			tempDirsMu.Unlock()
+		})
+	}
+
+	key := tempDirEnvKey(name, len(tempDirs[name]))
+
+	var dir string
+	if IsChild() {
+		dir = os.Getenv(key)
+		if dir == "" {
+			t.Fatalf("sandboxectest: %s is not set in helper process", key)
+		}
+	} else {
+		dir = t.TempDir()
+	}
+
+	tempDirs[name] = append(tempDirs[name], dir)
+
+	return dir
+}
+
+// IsChild reports whether the current process is a helper process started
+// by Run.
+func IsChild() bool {
+	_, ok := os.LookupEnv(envRun)
+
+	return ok
+}
+
+func runParent(t *testing.T, id string) {
+	t.Helper()
+
+	cmd := exec.CommandContext(t.Context(), os.Args[0], "-test.run="+runPattern(t.Name()), "-test.v")
+	cmd.Env = append(os.Environ(), envRun+"="+id)
+
+	// Directories created by the test or any of its parents are visible to
+	// the helper, which re-runs the whole chain.
+	tempDirsMu.Lock()
+	for name, dirs := range tempDirs {
+		if name != t.Name() && !strings.HasPrefix(t.Name(), name+"/") {
+			continue
+		}
+
+		for i, dir := range dirs {
+			cmd.Env = append(cmd.Env, tempDirEnvKey(name, i)+"="+dir)
+		}
+	}
+	tempDirsMu.Unlock()
+
+	out, err := cmd.CombinedOutput()
+	output := string(out)
+
+	if idx := strings.Index(output, skipMarker); idx >= 0 {
+		reason, _, _ := strings.Cut(output[idx+len(skipMarker):], "\n")
+		t.Skipf("sandboxed test body skipped: %s", reason)
+	}
+
+	if err != nil {
+		t.Fatalf("sandboxed test body failed: %v\n%s", err, output)
+	}
+
+	if !strings.Contains(output, doneMarker+id) {
+		t.Fatalf("sandboxed test body did not run\n%s", output)
+	}
+
+	if testing.Verbose() {
+		t.Log(strings.TrimSpace(output))
+	}
+}
+
+func runChild(t *testing.T, id string, opts []sandboxec.Option, body func(t *testing.T)) {
+	t.Helper()
+
+	if err := sandboxec.New(opts...).Enforce(); err != nil {
+		if isUnavailable(err) {
+			fmt.Fprintln(os.Stdout, skipMarker+"sandbox unavailable: "+err.Error())
+			t.SkipNow()
+		}
+
+		t.Fatalf("enforce sandbox policy: %v", err)
+	}
+
+	defer func() {
+		if t.Skipped() {
+			fmt.Fprintln(os.Stdout, skipMarker+"skipped in helper process")
+		}
+	}()
+
+	body(t)
+
+	fmt.Fprintln(os.Stdout, doneMarker+id)
+}
+
+func nextRunID(name string) string {
+	runCountsMu.Lock()
+	defer runCountsMu.Unlock()
+
+	n := runCounts[name]
+	runCounts[name] = n + 1
+
+	return name + "#" + strconv.Itoa(n)
+}
+
+func tempDirEnvKey(name string, index int) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+
+	return envTempDir + strconv.FormatUint(uint64(h.Sum32()), 16) + "_" + strconv.Itoa(index)
+}
+
+// runPattern returns a -test.run pattern that matches exactly name,
+// including its subtest path.
+func runPattern(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func isUnavailable(err error) bool {
+	return errors.Is(err, sandboxec.ErrLandlockUnavailable) ||
+		errors.Is(err, sandboxec.ErrABINotSupported) ||
+		errors.Is(err, sandboxec.ErrSeatbeltUnavailable)
+}
@@ -0,0 +1,73 @@
+// nolint
+//go:build linux
+// +build linux
+
+package sandboxectest_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.dw1.io/x/exp/sandboxec"
+	"go.dw1.io/x/exp/sandboxec/access"
+	"go.dw1.io/x/exp/sandboxec/sandboxectest"
+)
+
+func TestRunFilesystemPolicy(t *testing.T) {
+	allowed := sandboxectest.TempDir(t)
+	denied := sandboxectest.TempDir(t)
+
+	if !sandboxectest.IsChild() {
+		if err := os.WriteFile(filepath.Join(denied, "secret"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sandboxectest.Run(t, []sandboxec.Option{
+		sandboxec.WithFSRule(allowed, access.FS_READ_WRITE),
+	}, func(t *testing.T) {
+		sandboxectest.AssertReadable(t, allowed)
+		sandboxectest.AssertWritable(t, allowed)
+		sandboxectest.AssertNotReadable(t, filepath.Join(denied, "secret"))
+		sandboxectest.AssertNotWritable(t, denied)
+	})
+}
+
+func TestRunNetworkPolicy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	sandboxectest.Run(t, []sandboxec.Option{
+		sandboxec.WithABI(4),
+	}, func(t *testing.T) {
+		sandboxectest.AssertConnectDenied(t, listener.Addr().String())
+	})
+}
+
+func TestRunMultipleHelpers(t *testing.T) {
+	dir := sandboxectest.TempDir(t)
+
+	t.Run("read-only", func(t *testing.T) {
+		sandboxectest.Run(t, []sandboxec.Option{
+			sandboxec.WithFSRule(dir, access.FS_READ),
+		}, func(t *testing.T) {
+			sandboxectest.AssertReadable(t, dir)
+			sandboxectest.AssertNotWritable(t, dir)
+		})
+	})
+
+	t.Run("read-write", func(t *testing.T) {
+		sandboxectest.Run(t, []sandboxec.Option{
+			sandboxec.WithFSRule(dir, access.FS_READ_WRITE),
+		}, func(t *testing.T) {
+			sandboxectest.AssertWritable(t, dir)
+		})
+	})
+}
@@ -0,0 +1,11 @@
+// nolint
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package sandboxectest
+
+// Linux/Darwin-only package; this stub keeps `go test ./...` non-empty on unsupported OSes.
+
+func init() {
+	panic("sandboxectest: sandboxing is supported only on Linux (Landlock) and Darwin (Seatbelt)")
+}
@@ -0,0 +1,27 @@
+// nolint
+//go:build linux || darwin
+// +build linux darwin
+
+package sandboxec
+
+// ScopeStatus describes one requested scoped IPC restriction after
+// enforcement.
+type ScopeStatus struct {
+	// Name is the Landlock scope name, "abstract_unix_socket" or "signal".
+	Name string
+
+	// Enforced reports whether the running kernel applied the restriction.
+	// It is false only when best-effort mode skipped an unsupported scope.
+	Enforced bool
+}
+
+// Scopes reports the scoped IPC restrictions requested with
+// [WithRestrictScoped], [WithScopeAbstractUnixSocket] or [WithScopeSignal],
+// in Landlock flag order.
+//
+// It returns nil before enforcement, when enforcement failed, or when no
+// scope was requested. Scoped restrictions are unsupported on Darwin, so it
+// always returns nil there.
+func (s *Sandboxec) Scopes() []ScopeStatus {
+	return s.scopes
+}
@@ -0,0 +1,161 @@
+// nolint
+//go:build linux
+// +build linux
+
+package sandboxec
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// seccompArch describes how socket(2) is reached on one audit architecture.
+type seccompArch struct {
+	audit      uint32
+	socket     []uint32
+	socketcall []uint32
+	bigEndian  bool
+}
+
+// x32Bit marks x32 ABI syscalls, which share the x86_64 audit architecture.
+const x32Bit = 0x40000000
+
+// seccompArchTable lists, per GOARCH, the native architecture first and any
+// compat architecture whose binaries the kernel can run. Syscalls from other
+// architectures are denied outright, since their socket numbers are unknown.
+//
+// Every GOARCH listed here is cross-compiled for linux by the tests workflow;
+// keep that list in sync when adding one.
+var seccompArchTable = map[string][]seccompArch{
+	"amd64": {
+		{audit: unix.AUDIT_ARCH_X86_64, socket: []uint32{41, x32Bit | 41}},
+		{audit: unix.AUDIT_ARCH_I386, socket: []uint32{359}, socketcall: []uint32{102}},
+	},
+	"386": {
+		{audit: unix.AUDIT_ARCH_I386, socket: []uint32{359}, socketcall: []uint32{102}},
+	},
+	"arm64": {
+		{audit: unix.AUDIT_ARCH_AARCH64, socket: []uint32{198}},
+		{audit: unix.AUDIT_ARCH_ARM, socket: []uint32{281}},
+	},
+	"arm": {
+		{audit: unix.AUDIT_ARCH_ARM, socket: []uint32{281}},
+	},
+	"riscv64": {
+		{audit: unix.AUDIT_ARCH_RISCV64, socket: []uint32{198}},
+	},
+	"ppc64le": {
+		{audit: unix.AUDIT_ARCH_PPC64LE, socket: []uint32{326}, socketcall: []uint32{102}},
+	},
+	"s390x": {
+		{audit: unix.AUDIT_ARCH_S390X, socket: []uint32{359}, socketcall: []uint32{102}, bigEndian: true},
+	},
+	"loong64": {
+		{audit: unix.AUDIT_ARCH_LOONGARCH64, socket: []uint32{198}},
+	},
+}
+
+// Offsets into struct seccomp_data.
+const (
+	seccompDataNr   = 0
+	seccompDataArch = 4
+	seccompDataArg0 = 16
+)
+
+// seccompNetworkFilter returns a BPF program that fails socket(2) for
+// AF_INET and AF_INET6 with EACCES. socketcall(2) cannot be inspected, so it
+// is denied entirely on architectures that have it.
+func seccompNetworkFilter(arches []seccompArch) []unix.SockFilter {
+	deny := uint32(unix.SECCOMP_RET_ERRNO | uint32(syscall.EACCES))
+
+	var prog []unix.SockFilter
+
+	for _, arch := range arches {
+		n, m := len(arch.socket), len(arch.socketcall)
+
+		// Block layout: load arch, match arch, load nr, socket matches,
+		// socketcall matches, allow, load domain, two domain matches, allow,
+		// deny.
+		domain := 3 + n + m + 1
+		denyAt := domain + 4
+		size := denyAt + 1
+
+		argOff := uint32(seccompDataArg0)
+		if arch.bigEndian {
+			argOff += 4
+		}
+
+		prog = append(prog,
+			bpfLoad(seccompDataArch),
+			bpfJumpEq(arch.audit, 0, uint8(size-2)),
+			bpfLoad(seccompDataNr),
+		)
+
+		for i, nr := range arch.socket {
+			prog = append(prog, bpfJumpEq(nr, uint8(domain-(3+i+1)), 0))
+		}
+
+		for i, nr := range arch.socketcall {
+			prog = append(prog, bpfJumpEq(nr, uint8(denyAt-(3+n+i+1)), 0))
+		}
+
+		prog = append(prog,
+			bpfRet(unix.SECCOMP_RET_ALLOW),
+			bpfLoad(argOff),
+			bpfJumpEq(syscall.AF_INET, uint8(denyAt-(domain+2)), 0),
+			bpfJumpEq(syscall.AF_INET6, uint8(denyAt-(domain+3)), 0),
+			bpfRet(unix.SECCOMP_RET_ALLOW),
+			bpfRet(deny),
+		)
+	}
+
+	return append(prog, bpfRet(deny))
+}
+
+func bpfLoad(off uint32) unix.SockFilter {
+	return unix.SockFilter{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: off}
+}
+
+func bpfJumpEq(k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: jt, Jf: jf, K: k}
+}
+
+func bpfRet(k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: k}
+}
+
+// installSeccompNetworkDeny installs the network filter on all threads of
+// the current process.
+func installSeccompNetworkDeny() error {
+	arches, ok := seccompArchTable[goruntime.GOARCH]
+	if !ok {
+		return fmt.Errorf("%w: seccomp network fallback is unsupported on %s", ErrInvalidOption, goruntime.GOARCH)
+	}
+
+	filter := seccompNetworkFilter(arches)
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+
+	// no_new_privs is per thread; keep the filter install on the same one so
+	// TSYNC can propagate both to the other threads.
+	goruntime.LockOSThread()
+	defer goruntime.UnlockOSThread()
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("set no_new_privs: %w", err)
+	}
+
+	r, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER, unix.SECCOMP_FILTER_FLAG_TSYNC, uintptr(unsafe.Pointer(&prog)))
+	if errno != 0 {
+		return fmt.Errorf("seccomp network filter: %w", errno)
+	}
+
+	if r != 0 {
+		return fmt.Errorf("seccomp network filter: thread %d could not be synchronized", r)
+	}
+
+	return nil
+}